@@ -2,33 +2,121 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"flag"
+	"log"
 	"net/http"
+	"os"
 
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/game"
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/handler/http_handler"
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/handler/routes"
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/infra"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/boltdb"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/memory"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/mongodb"
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/redis"
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
 
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/handler/ws"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	store := flag.String("store", "redis", "where games are kept: redis for a cluster, memory for a single server")
+	userStore := flag.String("users", "bolt", "where registered users are kept: bolt for a local file, mongo for MongoDB at MONGO_URI")
+	userFile := flag.String("users-file", "users.db", "bolt file of the registered users")
+	chatFilter := flag.String("chat-filter", "", "file with one word per line to mask in chat messages")
+	seasonFile := flag.String("seasons", "", "JSON file with the ranked seasons, each with id, name, start and end")
+	match := services.DefaultMatchmakingConfig()
+	flag.IntVar(&match.Window, "match-window", match.Window, "rating points a queued player accepts an opponent within")
+	flag.IntVar(&match.WindowGrowth, "match-window-growth", match.WindowGrowth, "rating points the window grows every second of waiting")
+	flag.IntVar(&match.MaxWindow, "match-max-window", match.MaxWindow, "widest rating window")
+	flag.DurationVar(&match.RegionWait, "match-region-wait", match.RegionWait, "wait before players of other regions are paired")
+	flag.DurationVar(&match.MaxWait, "match-max-wait", match.MaxWait, "wait before a queued player gets the bot")
+	flag.StringVar(&match.BotDifficulty, "match-bot", match.BotDifficulty, "difficulty of the bot after the max wait, empty takes the player out of the queue")
 	flag.Parse()
-	
-	rdb := infra.CreateRedisClient("localhost:6379") // Add this in env
-	hub := ws.NewHub(rdb)
-	hs := services.CreateHttpService(rdb)
-	repo := redis.RedisGameRepository{
-		RedisClient: rdb,
+
+	if err := match.Validate(); err != nil {
+		log.Fatalf("matchmaking : %v", err)
+	}
+
+	ctx := context.Background()
+
+	var repo services.Repository
+	var broker infra.Broker
+	var expired <-chan string
+
+	switch *store {
+	case "memory":
+		mem := memory.NewMemoryGameRepository()
+		repo = mem
+		broker = infra.NewMemoryBroker()
+		expired = mem.Expired()
+	case "redis":
+		rdb := infra.CreateRedisClient("localhost:6379") // Add this in env
+		repo = &redis.RedisGameRepository{
+			RedisClient: rdb,
+		}
+		broker = infra.NewRedisBroker(rdb)
+		expired = game.RedisExpiredKeys(ctx,rdb)
+	default:
+		log.Fatalf("unknown store %q, use redis or memory", *store)
+	}
+
+	var users services.UserRepository
+	switch *userStore {
+	case "bolt":
+		local, err := boltdb.NewBoltUserRepository(*userFile)
+		if err != nil {
+			log.Fatalf("users file : %v", err)
+		}
+		defer local.Close()
+		users = local
+	case "mongo":
+		client := infra.CreateMongoClient(mongoURI())
+		mongoUsers, err := mongodb.NewMongoUserRepository(ctx,client.Database("space-striker"))
+		if err != nil {
+			log.Fatalf("MongoDB users : %v", err)
+		}
+		users = mongoUsers
+	default:
+		log.Fatalf("unknown user store %q, use bolt or mongo", *userStore)
 	}
+
+	hub := ws.NewHub(broker,repo)
+	hs := services.CreateHttpService(repo)
+	as := services.NewAuthService(sessionSecret(),users)
 	gs := services.NewGameService(repo,hub)
+	gs.SetUsers(users)
+	lb := services.NewLeaderboard(repo,users,loadSeasons(*seasonFile))
+	gs.SetLeaderboard(lb)
+	if *chatFilter != "" {
+		f, err := os.Open(*chatFilter)
+		if err != nil {
+			log.Fatalf("chat filter : %v", err)
+		}
+		filter, err := domain.ReadChatFilter(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("chat filter : %v", err)
+		}
+		gs.SetChatFilter(filter)
+	}
+	// every dependency of gs is set before the first goroutine can use it
+	ts := services.NewTournamentService(repo,hs,gs)
+	gs.SetTournaments(ts)
+	ls := services.NewLobbyService(repo,hs,hub)
+	gs.SetLobby(ls)
+
 	go hub.Run()
 
-	go game.ListenForTimeOut(context.Background(),rdb,gs)
+	go game.ListenForTimeOut(ctx,expired,gs)
+
+	mm := services.NewMatchmaker(repo,hs,gs,match)
+	go mm.Run(ctx)
+	go lb.Run(ctx)
 	
 	
 	router := gin.Default()
@@ -41,17 +129,73 @@ func main() {
 
 	v1 := router.Group("/api/v1")
 
-	routes.RoomRoutes(v1,httphandler.Handler{
+	h := httphandler.Handler{
 		HttpService: hs,
-	})
+		GameService: gs,
+		AuthService: as,
+		Matchmaker: mm,
+		Leaderboard: lb,
+		Tournaments: ts,
+		Lobby: ls,
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+	}
 
-	router.GET("/ws", wsHandler(hub,gs))
+	routes.AuthRoutes(v1,h)
+	routes.UserRoutes(v1,h)
+	routes.PlayerRoutes(v1,h)
+	routes.MatchmakingRoutes(v1,h)
+	routes.LeaderboardRoutes(v1,h)
+	routes.TournamentRoutes(v1,h)
+	routes.LobbyRoutes(v1,h)
+	routes.RoomRoutes(v1,h)
+	routes.GameRoutes(v1,h)
+	routes.AdminRoutes(v1,h)
+
+	router.GET("/ws", wsHandler(hub,gs,hs,as,mm))
 
 	router.Run(":8080")
 }
 
-func wsHandler(hub *ws.Hub,gs *services.GameService) gin.HandlerFunc {
+func wsHandler(hub *ws.Hub,gs *services.GameService,hs *services.HttpService,as *services.AuthService,mm *services.Matchmaker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		ws.ServerWs(hub,gs, ctx.Writer, ctx.Request)
+		ws.ServerWs(hub,gs,hs,as,mm, ctx.Writer, ctx.Request)
+	}
+}
+
+// sessionSecret signs the session tokens, all servers of a cluster need the same SESSION_SECRET.
+// Without one tokens are signed with a random secret and stop working when the server restarts.
+func sessionSecret() []byte {
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	log.Println("SESSION_SECRET is not set, using a random secret for this server only")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("session secret : %v", err)
+	}
+	return secret
+}
+
+// loadSeasons reads the seasons file, without one there are only the global leaderboards
+func loadSeasons(path string) []domain.Season {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("seasons : %v", err)
+	}
+	defer f.Close()
+	seasons, err := domain.ReadSeasons(f)
+	if err != nil {
+		log.Fatalf("seasons : %v", err)
+	}
+	return seasons
+}
+
+func mongoURI() string {
+	if uri := os.Getenv("MONGO_URI"); uri != "" {
+		return uri
 	}
+	return "mongodb://localhost:27017"
 }
@@ -0,0 +1,217 @@
+package bot
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+type Difficulty string
+
+const (
+	Random      Difficulty = "RANDOM"
+	HuntTarget  Difficulty = "HUNT_TARGET"
+	Probability Difficulty = "PROBABILITY"
+)
+
+const prefix = "bot-"
+
+var (
+	ErrInvalidDifficulty = errors.New("Invalid bot difficulty")
+	ErrNoLayout          = errors.New("Fleet does not fit on the board")
+)
+
+// View is what the bot knows about the opponent board, ship cells are hidden
+type View struct {
+	Board [][]domain.CellState
+	Sunk  []domain.PlacedShip
+	Fleet domain.Fleet
+}
+
+type Strategy interface {
+	NextShot(v View) domain.Point
+}
+
+func ParseDifficulty(d string) (Difficulty, error) {
+	switch Difficulty(strings.ToUpper(d)) {
+	case "":
+		return HuntTarget, nil
+	case Random:
+		return Random, nil
+	case HuntTarget:
+		return HuntTarget, nil
+	case Probability:
+		return Probability, nil
+	}
+	return "", ErrInvalidDifficulty
+}
+
+func New(d Difficulty) Strategy {
+	switch d {
+	case Random:
+		return randomStrategy{}
+	case Probability:
+		return probabilityStrategy{}
+	default:
+		return huntTargetStrategy{}
+	}
+}
+
+// PlayerID is the id the bot plays with in a room
+func PlayerID(roomID string) string {
+	return prefix + roomID
+}
+
+func IsBot(playerID string) bool {
+	return strings.HasPrefix(playerID, prefix)
+}
+
+// Salvo picks n different shots, every pick is treated as a miss for the next one
+func Salvo(s Strategy, v View, n int) []domain.Point {
+	board := copyBoard(v.Board)
+	shots := []domain.Point{}
+
+	for range n {
+		view := View{Board: board, Sunk: v.Sunk, Fleet: v.Fleet}
+		if len(unknownCells(board)) == 0 {
+			break
+		}
+		p := s.NextShot(view)
+		board[p.X][p.Y] = domain.Miss
+		shots = append(shots, p)
+	}
+	return shots
+}
+
+// maxPlacementAttempts bounds the random layouts tried before falling back to the packed one
+const maxPlacementAttempts = 100
+
+// PlaceFleet returns a random valid layout of the fleet for the rule set. Crowded boards
+// where random layouts keep failing get the packed layout of the fleet instead.
+func PlaceFleet(rules domain.RuleSet) ([]domain.ShipPlacement, error) {
+	for range maxPlacementAttempts {
+		if ships, ok := tryPlaceFleet(rules); ok {
+			return ships, nil
+		}
+	}
+	if ships, ok := rules.Fleet.Layout(rules.Width, rules.Height); ok {
+		return ships, nil
+	}
+	return nil, ErrNoLayout
+}
+
+func tryPlaceFleet(rules domain.RuleSet) ([]domain.ShipPlacement, bool) {
+	occupied := make(map[domain.Point]bool)
+	ships := make([]domain.ShipPlacement, 0, len(rules.Fleet))
+
+	for _, c := range rules.Fleet {
+		placed := false
+		for range 100 {
+			s := domain.ShipPlacement{
+				Origin:      domain.Point{X: rand.IntN(rules.Height), Y: rand.IntN(rules.Width)},
+				Length:      c.Length,
+				Orientation: domain.Horizontal,
+			}
+			if rand.IntN(2) == 0 {
+				s.Orientation = domain.Vertical
+			}
+
+			cells, _ := s.Cells()
+			if !fits(rules, occupied, cells) {
+				continue
+			}
+			for _, p := range cells {
+				occupied[p] = true
+			}
+			ships = append(ships, s)
+			placed = true
+			break
+		}
+		if !placed {
+			return nil, false
+		}
+	}
+	return ships, true
+}
+
+func fits(rules domain.RuleSet, occupied map[domain.Point]bool, cells []domain.Point) bool {
+	for _, p := range cells {
+		if !rules.InBounds(p) || occupied[p] {
+			return false
+		}
+	}
+	return true
+}
+
+func copyBoard(board [][]domain.CellState) [][]domain.CellState {
+	c := make([][]domain.CellState, len(board))
+	for i := range board {
+		c[i] = append([]domain.CellState(nil), board[i]...)
+	}
+	return c
+}
+
+func unknownCells(board [][]domain.CellState) []domain.Point {
+	cells := []domain.Point{}
+	for i := range board {
+		for j := range board[i] {
+			if board[i][j] != domain.Hit && board[i][j] != domain.Miss {
+				cells = append(cells, domain.Point{X: i, Y: j})
+			}
+		}
+	}
+	return cells
+}
+
+func inBoard(board [][]domain.CellState, p domain.Point) bool {
+	return p.X >= 0 && p.X < len(board) && p.Y >= 0 && p.Y < len(board[p.X])
+}
+
+func isUnknown(board [][]domain.CellState, p domain.Point) bool {
+	return inBoard(board, p) && board[p.X][p.Y] != domain.Hit && board[p.X][p.Y] != domain.Miss
+}
+
+// openHits are hits that do not belong to a ship that is already sunk
+func openHits(v View) []domain.Point {
+	sunk := make(map[domain.Point]bool)
+	for _, s := range v.Sunk {
+		for _, p := range s.Cells {
+			sunk[p] = true
+		}
+	}
+
+	hits := []domain.Point{}
+	for i := range v.Board {
+		for j := range v.Board[i] {
+			p := domain.Point{X: i, Y: j}
+			if v.Board[i][j] == domain.Hit && !sunk[p] {
+				hits = append(hits, p)
+			}
+		}
+	}
+	return hits
+}
+
+// afloat returns the lengths of the ships that are not sunk yet
+func afloat(v View) []int {
+	sunk := make(map[int]int)
+	for _, s := range v.Sunk {
+		sunk[len(s.Cells)]++
+	}
+
+	lengths := []int{}
+	for _, c := range v.Fleet {
+		if sunk[c.Length] > 0 {
+			sunk[c.Length]--
+			continue
+		}
+		lengths = append(lengths, c.Length)
+	}
+	return lengths
+}
+
+func pick(cells []domain.Point) domain.Point {
+	return cells[rand.IntN(len(cells))]
+}
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// placeFleet places the fleet for a player and fails the test when there is no valid layout
+func placeFleet(t *testing.T, game *domain.Game, playerID string) {
+	t.Helper()
+	ships, err := PlaceFleet(game.Rules)
+	if err != nil {
+		t.Fatalf("expected a layout for %+v but got %v", game.Rules, err)
+	}
+	if err := game.AddShip(playerID, ships); err != nil {
+		t.Fatalf("expected valid random fleet but got %v", err)
+	}
+}
+
+func TestPlaceFleet(t *testing.T) {
+	rules := domain.DefaultRuleSet()
+
+	for range 50 {
+		placeFleet(t, domain.NewGame("A", "B", "123", rules), "A")
+	}
+}
+
+// every rule set Validate accepts must be placeable, however crowded the board
+func TestPlaceFleetAcceptedRules(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	accepted := 0
+
+	for range 2000 {
+		rules := domain.DefaultRuleSet()
+		rules.Width, rules.Height = 5+r.IntN(4), 5+r.IntN(4)
+		rules.Fleet = nil
+		for cells := 0; cells < rules.Width*rules.Height*3/4; {
+			length := 1 + r.IntN(max(rules.Width, rules.Height))
+			rules.Fleet = append(rules.Fleet, domain.ShipClass{Name: "ship", Length: length})
+			cells += length
+		}
+		if rules.Validate() != nil {
+			continue
+		}
+		accepted++
+		placeFleet(t, domain.NewGame("A", "B", "123", rules), "A")
+	}
+	if accepted == 0 {
+		t.Fatal("expected some of the generated rule sets to be accepted")
+	}
+
+	// fits by area but there is no layout at all
+	full := domain.DefaultRuleSet()
+	full.Width, full.Height = 5, 5
+	full.Fleet = domain.Fleet{{Name: "a", Length: 5}, {Name: "b", Length: 5}, {Name: "c", Length: 5}, {Name: "d", Length: 4}, {Name: "e", Length: 3}, {Name: "f", Length: 3}}
+	if _, err := PlaceFleet(full); err != ErrNoLayout {
+		t.Errorf("impossible fleet: expected %v, got %v", ErrNoLayout, err)
+	}
+}
+
+func TestStrategiesPlayFullGame(t *testing.T) {
+	for _, d := range []Difficulty{Random, HuntTarget, Probability} {
+		t.Run(string(d), func(t *testing.T) {
+			rules := domain.DefaultRuleSet()
+			rules.ExtraShotOnHit = true
+
+			game := domain.NewGame("bot", "human", "123", rules)
+			placeFleet(t, game, "bot")
+			placeFleet(t, game, "human")
+			game.Status = domain.StatusActive
+
+			strategy := New(d)
+			cells := rules.Width * rules.Height
+
+			for shots := 0; !game.CheckWinner("bot"); shots++ {
+				if shots >= cells {
+					t.Fatalf("expected game to finish in %d shots", cells)
+				}
+				game.ActivePlayer = "bot"
+				view := View{
+					Board: game.HideOpponentShips("bot"),
+					Sunk:  game.SunkShips("human"),
+					Fleet: rules.Fleet,
+				}
+				if _, err := game.HandleShot("bot", strategy.NextShot(view)); err != nil {
+					t.Fatalf("expected valid shot but got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestHuntTargetFollowsHit(t *testing.T) {
+	rules := domain.DefaultRuleSet()
+	board := make([][]domain.CellState, rules.Height)
+	for i := range board {
+		board[i] = make([]domain.CellState, rules.Width)
+	}
+	board[4][4] = domain.Hit
+	board[4][5] = domain.Hit
+	board[4][6] = domain.Miss
+
+	view := View{Board: board, Fleet: rules.Fleet}
+	for range 20 {
+		shot := huntTargetStrategy{}.NextShot(view)
+		if shot != (domain.Point{X: 4, Y: 3}) {
+			t.Fatalf("expected shot along the hit line at (4,3) but got %v", shot)
+		}
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	d, err := ParseDifficulty("probability")
+	if err != nil || d != Probability {
+		t.Fatalf("expected %s but got %s, %v", Probability, d, err)
+	}
+
+	if _, err := ParseDifficulty("godlike"); err != ErrInvalidDifficulty {
+		t.Fatalf("expected %v but got %v", ErrInvalidDifficulty, err)
+	}
+}
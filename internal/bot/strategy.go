@@ -0,0 +1,134 @@
+package bot
+
+import "github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+
+var directions = []domain.Point{{X: -1, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: -1}, {X: 0, Y: 1}}
+
+// randomStrategy fires at any cell it has not fired at yet
+type randomStrategy struct{}
+
+func (randomStrategy) NextShot(v View) domain.Point {
+	cells := unknownCells(v.Board)
+	if len(cells) == 0 {
+		return domain.Point{}
+	}
+	return pick(cells)
+}
+
+// huntTargetStrategy hunts on a checkerboard until it hits, then targets the cells around the hit
+type huntTargetStrategy struct{}
+
+func (huntTargetStrategy) NextShot(v View) domain.Point {
+	hits := openHits(v)
+	hitSet := make(map[domain.Point]bool)
+	for _, h := range hits {
+		hitSet[h] = true
+	}
+
+	// two hits next to each other give the direction of the ship, keep going along that line
+	inLine := []domain.Point{}
+	around := []domain.Point{}
+	for _, h := range hits {
+		for _, d := range directions {
+			next := domain.Point{X: h.X + d.X, Y: h.Y + d.Y}
+			if !isUnknown(v.Board, next) {
+				continue
+			}
+			around = append(around, next)
+			if hitSet[domain.Point{X: h.X - d.X, Y: h.Y - d.Y}] {
+				inLine = append(inLine, next)
+			}
+		}
+	}
+
+	if len(inLine) > 0 {
+		return pick(inLine)
+	}
+	if len(around) > 0 {
+		return pick(around)
+	}
+
+	smallest := 1
+	if lengths := afloat(v); len(lengths) > 0 {
+		smallest = lengths[0]
+		for _, l := range lengths {
+			smallest = min(smallest, l)
+		}
+	}
+
+	// every ship of length n covers at least one cell where (x+y)%n == 0
+	parity := []domain.Point{}
+	for _, p := range unknownCells(v.Board) {
+		if (p.X+p.Y)%smallest == 0 {
+			parity = append(parity, p)
+		}
+	}
+	if len(parity) > 0 {
+		return pick(parity)
+	}
+	return randomStrategy{}.NextShot(v)
+}
+
+// probabilityStrategy counts for every cell how many ways the remaining ships can cover it
+// and fires at the most likely one, layouts that go through open hits count much more.
+type probabilityStrategy struct{}
+
+func (probabilityStrategy) NextShot(v View) domain.Point {
+	hits := make(map[domain.Point]bool)
+	for _, h := range openHits(v) {
+		hits[h] = true
+	}
+
+	density := make(map[domain.Point]int)
+	for _, length := range afloat(v) {
+		for x := range v.Board {
+			for y := range v.Board[x] {
+				for _, o := range []domain.Orientation{domain.Horizontal, domain.Vertical} {
+					cells, _ := domain.ShipPlacement{Origin: domain.Point{X: x, Y: y}, Length: length, Orientation: o}.Cells()
+					weight, ok := layoutWeight(v.Board, hits, cells)
+					if !ok {
+						continue
+					}
+					for _, c := range cells {
+						if isUnknown(v.Board, c) {
+							density[c] += weight
+						}
+					}
+				}
+			}
+		}
+	}
+
+	best := []domain.Point{}
+	bestScore := 0
+	for p, score := range density {
+		if score > bestScore {
+			best = []domain.Point{p}
+			bestScore = score
+		} else if score == bestScore {
+			best = append(best, p)
+		}
+	}
+
+	if len(best) == 0 {
+		return randomStrategy{}.NextShot(v)
+	}
+	return pick(best)
+}
+
+// layoutWeight rejects layouts over misses or sunk ships and rewards layouts through open hits
+func layoutWeight(board [][]domain.CellState, hits map[domain.Point]bool, cells []domain.Point) (int, bool) {
+	weight := 1
+	for _, c := range cells {
+		if !inBoard(board, c) || board[c.X][c.Y] == domain.Miss {
+			return 0, false
+		}
+		if board[c.X][c.Y] == domain.Hit {
+			if !hits[c] {
+				return 0, false
+			}
+			weight += 20
+		}
+	}
+	return weight, true
+}
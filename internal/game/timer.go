@@ -8,20 +8,43 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-func ListenForTimeOut(ctx context.Context, rdb *redis.Client, gs *services.GameService) {
-    pubsub := rdb.Subscribe(ctx, "__keyevent@0__:expired")
-    defer pubsub.Close()
-    log.Println("Listening for Redis move/game timeouts...")
+// RedisExpiredKeys streams the keys Redis reports as expired, keyspace events must be enabled
+func RedisExpiredKeys(ctx context.Context, rdb *redis.Client) <-chan string {
+    keys := make(chan string)
+    go func() {
+        defer close(keys)
+        pubsub := rdb.Subscribe(ctx, "__keyevent@0__:expired")
+        defer pubsub.Close()
 
-    ch := pubsub.Channel()
+        ch := pubsub.Channel()
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case msg := <-ch:
+                select {
+                case keys <- msg.Payload:
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }
+    }()
+    return keys
+}
+
+func ListenForTimeOut(ctx context.Context, keys <-chan string, gs *services.GameService) {
+    log.Println("Listening for move/game timeouts...")
 
     for {
         select {
         case <-ctx.Done():
-            log.Println("Stopping Redis timeout listener...")
+            log.Println("Stopping timeout listener...")
             return
-        case msg := <-ch:
-            key := msg.Payload
+        case key, ok := <-keys:
+            if !ok {
+                return
+            }
 
             parts := strings.Split(key, ":")
             if len(parts) < 2 {
@@ -43,6 +66,8 @@ func ListenForTimeOut(ctx context.Context, rdb *redis.Client, gs *services.GameS
                 gs.HandlePlaceTimeOut(gameID)
             case "game":
                 gs.HandleGameLimit(gameID)
+            case "room":
+                gs.HandleRoomExpired(gameID)
             default:
                 // Ignore keys we don't care about
                 continue
@@ -0,0 +1,22 @@
+package httphandler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin lets through requests with the admin token as Bearer token,
+// without a configured token every admin request is refused
+func (h Handler) RequireAdmin(ctx *gin.Context) {
+	token, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if h.AdminToken == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.AdminToken)) != 1 {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "Admin token required",
+		})
+		return
+	}
+	ctx.Next()
+}
@@ -1,2 +1,97 @@
 package httphandler
 
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// sessionKey holds the claims of the session in the gin context
+const sessionKey = "session"
+
+// CreateSession logs a user in or starts a guest session and returns its token
+func (h Handler) CreateSession(ctx *gin.Context) {
+	var req models.SessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var session models.SessionResponse
+	var err error
+	if req.Username != "" {
+		session, err = h.AuthService.Login(ctx.Request.Context(), req.Username, req.Password)
+	} else {
+		session, err = h.AuthService.GuestSession(req.Name)
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrInvalidName):
+			status = http.StatusBadRequest
+		case errors.Is(err, domain.ErrInvalidCredentials):
+			status = http.StatusUnauthorized
+		}
+		ctx.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusCreated, session)
+}
+
+// Session reads the session token of the request when there is one,
+// a token that does not verify is rejected instead of being ignored
+func (h Handler) Session(ctx *gin.Context) {
+	token := services.TokenFromRequest(ctx.Request)
+	if token == "" {
+		ctx.Next()
+		return
+	}
+
+	claims, err := h.AuthService.VerifyToken(token)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.Set(sessionKey, claims)
+	ctx.Next()
+}
+
+// RequireSession rejects requests without a valid session token
+func (h Handler) RequireSession(ctx *gin.Context) {
+	if _, ok := ctx.Get(sessionKey); !ok {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": services.ErrInvalidToken.Error(),
+		})
+		return
+	}
+	ctx.Next()
+}
+
+// sessionClaims returns the claims of the session the request was made with
+func sessionClaims(ctx *gin.Context) (*services.SessionClaims, bool) {
+	claims, ok := ctx.Get(sessionKey)
+	if !ok {
+		return nil, false
+	}
+	return claims.(*services.SessionClaims), true
+}
+
+// sessionPlayer returns the player of the session the request was made with
+func sessionPlayer(ctx *gin.Context) (string, bool) {
+	claims, ok := sessionClaims(ctx)
+	if !ok {
+		return "", false
+	}
+	return claims.Subject, true
+}
@@ -0,0 +1,31 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func (h Handler) GameReplay(ctx *gin.Context) {
+	// round is optional, without it the latest round of the room is replayed
+	round, _ := strconv.Atoi(ctx.Query("round"))
+
+	replay, err := h.GameService.GameReplay(ctx.Request.Context(), ctx.Param("id"), round)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrReplayNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrGameInProgress):
+			status = http.StatusConflict
+		}
+		ctx.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, replay)
+}
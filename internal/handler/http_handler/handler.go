@@ -8,5 +8,13 @@ import (
 
 type Handler struct {
 	HttpService *services.HttpService
+	GameService *services.GameService
+	AuthService *services.AuthService
+	Matchmaker  *services.Matchmaker
+	Leaderboard *services.Leaderboard
+	Tournaments *services.TournamentService
+	Lobby       *services.LobbyService
+	// AdminToken opens the admin endpoints, they stay closed when it is empty
+	AdminToken  string
 }
 
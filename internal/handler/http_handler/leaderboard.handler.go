@@ -0,0 +1,65 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// LeaderboardPage shows a page of a board, ?board=rating|wins|streak&season=current|global|<id>&offset=&limit=,
+// signed in callers also get their own place
+func (h Handler) LeaderboardPage(ctx *gin.Context) {
+	offset, err := queryInt(ctx, "offset")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": services.ErrInvalidPage.Error(),
+		})
+		return
+	}
+	limit, err := queryInt(ctx, "limit")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": services.ErrInvalidPage.Error(),
+		})
+		return
+	}
+
+	player, _ := sessionPlayer(ctx)
+	page, err := h.Leaderboard.Page(ctx.Request.Context(), ctx.Query("board"), ctx.Query("season"), offset, limit, player)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrSeasonNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, domain.ErrInvalidBoard), errors.Is(err, services.ErrInvalidPage):
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// Seasons lists every season, past seasons keep their leaderboards
+func (h Handler) Seasons(ctx *gin.Context) {
+	seasons := h.Leaderboard.Seasons()
+	if seasons == nil {
+		seasons = []domain.Season{}
+	}
+	ctx.JSON(http.StatusOK, seasons)
+}
+
+// queryInt reads an optional integer query parameter, missing ones are 0
+func queryInt(ctx *gin.Context, name string) (int64, error) {
+	v := ctx.Query(name)
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
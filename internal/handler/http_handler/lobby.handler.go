@@ -0,0 +1,40 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LobbyRooms lists the public rooms, ?offset=&limit=, newest first
+func (h Handler) LobbyRooms(ctx *gin.Context) {
+	offset, err := queryInt(ctx, "offset")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": services.ErrInvalidPage.Error(),
+		})
+		return
+	}
+	limit, err := queryInt(ctx, "limit")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": services.ErrInvalidPage.Error(),
+		})
+		return
+	}
+
+	lobby, err := h.Lobby.List(ctx.Request.Context(), offset, limit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidPage) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, lobby)
+}
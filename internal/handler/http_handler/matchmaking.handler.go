@@ -0,0 +1,61 @@
+package httphandler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// JoinQueue puts the signed in player into the matchmaking queue, MATCH_FOUND
+// arrives on the lobby connection of the player
+func (h Handler) JoinQueue(ctx *gin.Context) {
+	var req models.QueueRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	player, _ := sessionPlayer(ctx)
+	queued, err := h.Matchmaker.Enqueue(ctx.Request.Context(), player, req.Region)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidRegion) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, queued)
+}
+
+// LeaveQueue takes the signed in player out of the matchmaking queue
+func (h Handler) LeaveQueue(ctx *gin.Context) {
+	player, _ := sessionPlayer(ctx)
+	if err := h.Matchmaker.Leave(ctx.Request.Context(), player); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, models.QueuePayload{Queued: false})
+}
+
+// QueueStatus shows the matchmaking queue and its settings to admins
+func (h Handler) QueueStatus(ctx *gin.Context) {
+	status, err := h.Matchmaker.QueueStatus(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, status)
+}
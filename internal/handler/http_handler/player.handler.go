@@ -0,0 +1,17 @@
+package httphandler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlayerRating shows the rating of a registered player
+func (h Handler) PlayerRating(ctx *gin.Context) {
+	rating, err := h.AuthService.GetRating(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rating)
+}
@@ -1,20 +1,101 @@
 package httphandler
 
 import (
+	"errors"
+	"io"
 	"net/http"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/bot"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
 	"github.com/gin-gonic/gin"
-	
 )
 
+// RoomCreate creates a room, POST may carry a rule set, fields left out keep their default value
 func (h Handler) RoomCreate(ctx *gin.Context)  {
-	roomId ,err := h.HttpService.RoomGenerator()
+	req := models.CreateRoomRequest{
+		Rules: domain.DefaultRuleSet(),
+	}
+
+	if ctx.Request.Method == http.MethodPost {
+		if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			ctx.JSON(http.StatusBadRequest,gin.H{
+				"error":err.Error(),
+			})
+			return
+		}
+	}
+
+	// only a signed in player can be the creator, anonymous rooms have none
+	req.Creator, _ = sessionPlayer(ctx)
+
+	room ,err := h.HttpService.RoomGenerator(req)
 	if err != nil {
-		ctx.JSON(500,gin.H{
-			"error":err,
+		status := http.StatusInternalServerError
+		if isRequestError(err) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status,gin.H{
+			"error":err.Error(),
 		})
 		return
 	}
+	h.Lobby.RoomChanged(ctx.Request.Context(),room.ID,models.LobbyOpened)
 	ctx.JSON(http.StatusCreated,gin.H{
-		"roomID":roomId,
+		"roomID":room.ID,
+		"rules":room.Rules,
+		"bestOf":room.BestOf,
+		"visibility":room.Visibility,
+		"expiresAt":room.ExpiresAt,
 	})
-}
\ No newline at end of file
+}
+
+// RoomInfo shows a room before joining it, expired rooms are gone
+func (h Handler) RoomInfo(ctx *gin.Context) {
+	room, err := h.HttpService.RoomInfo(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrRoomNotFound) {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, room)
+}
+
+// RoomChat pages back through the chat of a room for one of its players,
+// ?before= takes the nextBefore of the previous page
+func (h Handler) RoomChat(ctx *gin.Context) {
+	player, _ := sessionPlayer(ctx)
+	page, err := h.HttpService.ChatHistory(ctx.Request.Context(), ctx.Param("id"), player, ctx.Query("before"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrRoomNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, domain.ErrInvalidChatID) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, services.ErrNotInRoom) {
+			status = http.StatusForbidden
+		}
+		ctx.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+func isRequestError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidBoardSize) ||
+		errors.Is(err, domain.ErrInvalidFleet) ||
+		errors.Is(err, domain.ErrInvalidTimeLimit) ||
+		errors.Is(err, domain.ErrInvalidMode) ||
+		errors.Is(err, domain.ErrInvalidBestOf) ||
+		errors.Is(err, domain.ErrInvalidVisibility) ||
+		errors.Is(err, bot.ErrInvalidDifficulty) ||
+		errors.Is(err, services.ErrInvalidOpponent)
+}
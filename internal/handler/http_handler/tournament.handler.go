@@ -0,0 +1,118 @@
+package httphandler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTournament opens a tournament for registration, the caller organizes it
+func (h Handler) CreateTournament(ctx *gin.Context) {
+	req := models.CreateTournamentRequest{
+		Rules: domain.DefaultRuleSet(),
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	player, _ := sessionPlayer(ctx)
+	t, err := h.Tournaments.Create(ctx.Request.Context(), player, req)
+	if err != nil {
+		tournamentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, t)
+}
+
+// Tournament shows the bracket of a tournament
+func (h Handler) Tournament(ctx *gin.Context) {
+	t, err := h.Tournaments.Get(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		tournamentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, t)
+}
+
+// RegisterTournamentPlayer signs the caller up, the organizer may name another player
+func (h Handler) RegisterTournamentPlayer(ctx *gin.Context) {
+	var req models.RegisterPlayerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	player, _ := sessionPlayer(ctx)
+	t, err := h.Tournaments.Register(ctx.Request.Context(), ctx.Param("id"), player, req.PlayerID)
+	if err != nil {
+		tournamentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, t)
+}
+
+// StartTournament draws the bracket and opens the rooms of the first round
+func (h Handler) StartTournament(ctx *gin.Context) {
+	player, _ := sessionPlayer(ctx)
+	t, err := h.Tournaments.Start(ctx.Request.Context(), ctx.Param("id"), player)
+	if err != nil {
+		tournamentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, t)
+}
+
+// ReportTournamentMatch lets the organizer decide a match
+func (h Handler) ReportTournamentMatch(ctx *gin.Context) {
+	var req models.ReportMatchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	player, _ := sessionPlayer(ctx)
+	t, err := h.Tournaments.Report(ctx.Request.Context(), ctx.Param("id"), player, ctx.Param("match"), req.Winner)
+	if err != nil {
+		tournamentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, t)
+}
+
+func tournamentError(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrTournamentNotFound), errors.Is(err, domain.ErrMatchNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrNotOrganizer):
+		status = http.StatusForbidden
+	case errors.Is(err, domain.ErrRegistrationClosed),
+		errors.Is(err, domain.ErrAlreadyRegistered),
+		errors.Is(err, domain.ErrTournamentFull),
+		errors.Is(err, domain.ErrNotEnoughPlayers),
+		errors.Is(err, domain.ErrMatchNotPlayable),
+		errors.Is(err, domain.ErrTournamentConflict):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidFormat),
+		errors.Is(err, domain.ErrInvalidTournamentName),
+		errors.Is(err, domain.ErrTournamentSize),
+		errors.Is(err, domain.ErrNotInMatch),
+		isRequestError(err):
+		status = http.StatusBadRequest
+	}
+	ctx.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,94 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Register creates a user and answers with a session like logging in does
+func (h Handler) Register(ctx *gin.Context) {
+	var req models.RegisterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	session, err := h.AuthService.Register(ctx.Request.Context(), req)
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, session)
+}
+
+// UserProfile shows the public profile of a user
+func (h Handler) UserProfile(ctx *gin.Context) {
+	user, err := h.AuthService.GetProfile(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+// MyProfile shows the profile of the signed in user
+func (h Handler) MyProfile(ctx *gin.Context) {
+	claims, _ := sessionClaims(ctx)
+	if claims.Guest {
+		userError(ctx, services.ErrGuestProfile)
+		return
+	}
+
+	user, err := h.AuthService.GetProfile(ctx.Request.Context(), claims.Subject)
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+// UpdateMyProfile changes the display name or avatar of the signed in user
+func (h Handler) UpdateMyProfile(ctx *gin.Context) {
+	var req models.ProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	claims, _ := sessionClaims(ctx)
+	user, err := h.AuthService.UpdateProfile(ctx.Request.Context(), claims, req)
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+func userError(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrUsernameTaken):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrGuestProfile):
+		status = http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidUsername),
+		errors.Is(err, domain.ErrInvalidPassword),
+		errors.Is(err, domain.ErrInvalidDisplayName),
+		errors.Is(err, domain.ErrInvalidAvatarURL):
+		status = http.StatusBadRequest
+	}
+	ctx.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
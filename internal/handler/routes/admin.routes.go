@@ -0,0 +1,11 @@
+package routes
+
+import (
+	httphandler "github.com/Harish-Naruto/Space-Striker-Server/internal/handler/http_handler"
+	"github.com/gin-gonic/gin"
+)
+
+func AdminRoutes(router *gin.RouterGroup, h httphandler.Handler) {
+	admin := router.Group("/admin", h.RequireAdmin)
+	admin.GET("/matchmaking/queue", h.QueueStatus)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	httphandler "github.com/Harish-Naruto/Space-Striker-Server/internal/handler/http_handler"
+	"github.com/gin-gonic/gin"
+)
+
+func LeaderboardRoutes(router *gin.RouterGroup, h httphandler.Handler) {
+	router.GET("/leaderboard", h.Session, h.LeaderboardPage)
+	router.GET("/seasons", h.Seasons)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	httphandler "github.com/Harish-Naruto/Space-Striker-Server/internal/handler/http_handler"
+	"github.com/gin-gonic/gin"
+)
+
+func MatchmakingRoutes(router *gin.RouterGroup, h httphandler.Handler) {
+	router.POST("/matchmaking/queue", h.Session, h.RequireSession, h.JoinQueue)
+	router.DELETE("/matchmaking/queue", h.Session, h.RequireSession, h.LeaveQueue)
+}
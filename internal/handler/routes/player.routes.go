@@ -0,0 +1,10 @@
+package routes
+
+import (
+	httphandler "github.com/Harish-Naruto/Space-Striker-Server/internal/handler/http_handler"
+	"github.com/gin-gonic/gin"
+)
+
+func PlayerRoutes(router *gin.RouterGroup, h httphandler.Handler) {
+	router.GET("/players/:id/rating", h.PlayerRating)
+}
@@ -6,5 +6,8 @@ import (
 )
 
 func RoomRoutes(router *gin.RouterGroup, h httphandler.Handler)  {
-	router.GET("/room",h.RoomCreate)
+	router.GET("/room",h.Session,h.RoomCreate)
+	router.POST("/room",h.Session,h.RoomCreate)
+	router.GET("/room/:id",h.RoomInfo)
+	router.GET("/room/:id/chat",h.Session,h.RequireSession,h.RoomChat)
 }
\ No newline at end of file
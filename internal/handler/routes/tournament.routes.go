@@ -0,0 +1,14 @@
+package routes
+
+import (
+	httphandler "github.com/Harish-Naruto/Space-Striker-Server/internal/handler/http_handler"
+	"github.com/gin-gonic/gin"
+)
+
+func TournamentRoutes(router *gin.RouterGroup, h httphandler.Handler) {
+	router.POST("/tournaments", h.Session, h.RequireSession, h.CreateTournament)
+	router.GET("/tournaments/:id", h.Tournament)
+	router.POST("/tournaments/:id/players", h.Session, h.RequireSession, h.RegisterTournamentPlayer)
+	router.POST("/tournaments/:id/start", h.Session, h.RequireSession, h.StartTournament)
+	router.POST("/tournaments/:id/matches/:match/winner", h.Session, h.RequireSession, h.ReportTournamentMatch)
+}
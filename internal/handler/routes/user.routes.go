@@ -0,0 +1,13 @@
+package routes
+
+import (
+	httphandler "github.com/Harish-Naruto/Space-Striker-Server/internal/handler/http_handler"
+	"github.com/gin-gonic/gin"
+)
+
+func UserRoutes(router *gin.RouterGroup, h httphandler.Handler) {
+	router.POST("/users", h.Register)
+	router.GET("/users/me", h.Session, h.RequireSession, h.MyProfile)
+	router.PATCH("/users/me", h.Session, h.RequireSession, h.UpdateMyProfile)
+	router.GET("/users/:id", h.UserProfile)
+}
@@ -2,6 +2,7 @@ package ws
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
@@ -16,7 +19,10 @@ const (
 	writeWait      = 10 * time.Second
 	pongWait       = 1 * time.Minute
 	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 1024
+	// maxMessageSize fits the largest message the rules allow, a fleet of one-cell ships
+	// or a salvo covering the biggest board, each entry taking at most maxEntrySize of JSON
+	maxMessageSize = domain.MaxBoardSize*domain.MaxBoardSize*maxEntrySize + 1024
+	maxEntrySize   = 128
 )
 
 var (
@@ -31,11 +37,20 @@ type Client struct {
 	roomId string
 	gs *services.GameService
 	playerID string
+	spectator bool
+	delay time.Duration
+	// lobby clients are not in a room, they wait for matchmaking
+	lobby bool
+	mm *services.Matchmaker
 }
 
 // readPump read message from the client and broadcast them into hub
 func (c *Client) readPump() {
 	defer func() {
+		// nobody can be told about a match once the lobby connection is gone
+		if c.lobby {
+			c.mm.Leave(context.Background(),c.playerID)
+		}
 		c.hub.Unregister <- c
 		c.conn.Close()
 	}()
@@ -51,7 +66,11 @@ func (c *Client) readPump() {
 			break
 		}
 		message = bytes.TrimSpace(bytes.Replace(message, newline, space, -1))
-		if err := MessageHandler(message,c.roomId,c.gs,c.playerID); err!=nil{
+		if c.lobby {
+			LobbyMessageHandler(message,c.mm,c.playerID)
+			continue
+		}
+		if err := MessageHandler(message,c.roomId,c.gs,c.playerID,c.spectator); err!=nil{
 			log.Print(err)
 		}
 
@@ -98,7 +117,7 @@ func (c *Client) writePump() {
 	}
 }
 
-func ServerWs(h *Hub, gs *services.GameService, w http.ResponseWriter, r *http.Request) {
+func ServerWs(h *Hub, gs *services.GameService, hs *services.HttpService, as *services.AuthService, mm *services.Matchmaker, w http.ResponseWriter, r *http.Request) {
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:    1024,
 		WriteBufferSize:   1024,
@@ -109,11 +128,43 @@ func ServerWs(h *Hub, gs *services.GameService, w http.ResponseWriter, r *http.R
 	}
 
 
+	if r.URL.Query().Get("mode") == "replay" {
+		ServeReplay(gs,upgrader,w,r)
+		return
+	}
+
+	if r.URL.Query().Get("mode") == "lobby" {
+		ServeLobby(h,gs,as,mm,upgrader,w,r)
+		return
+	}
+
 	roomID := r.URL.Query().Get("roomID")
-	playerID := r.URL.Query().Get("playerID")
+	spectator := r.URL.Query().Get("role") == "spectator"
 
-	if roomID=="" || playerID=="" {
-		log.Println("roomID or PlayerID is missing")
+	if roomID=="" {
+		http.Error(w, "roomID is missing", http.StatusBadRequest)
+		return
+	}
+
+	// spectators do not play, they get their own id so they never take a player's place.
+	// players are who their session token says, never who the query says
+	var playerID string
+	var delay time.Duration
+	if spectator {
+		playerID = "spectator-" + uuid.NewString()
+		delay = gs.SpectatorDelay(r.Context(),roomID)
+	} else {
+		claims, err := as.VerifyToken(services.TokenFromRequest(r))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		playerID = claims.Subject
+	}
+
+	// only rooms created through the API and not expired yet can be joined
+	if !hs.RoomValidator(roomID) {
+		http.Error(w, domain.ErrRoomNotFound.Error(), http.StatusNotFound)
 		return
 	}
 	
@@ -132,6 +183,8 @@ func ServerWs(h *Hub, gs *services.GameService, w http.ResponseWriter, r *http.R
 		roomId: roomID,
 		gs: gs,
 		playerID: playerID,
+		spectator: spectator,
+		delay: delay,
 	}
 	// this spawns the read and write thread for each client to send and receive messages
 	go client.readPump()
@@ -161,3 +214,33 @@ func ServerWs(h *Hub, gs *services.GameService, w http.ResponseWriter, r *http.R
 	h.Register <- client
 }
 
+
+// ServeLobby connects a player that is not in a room yet, it can queue for
+// matchmaking and gets MATCH_FOUND once it has an opponent
+func ServeLobby(h *Hub, gs *services.GameService, as *services.AuthService, mm *services.Matchmaker, upgrader websocket.Upgrader, w http.ResponseWriter, r *http.Request) {
+	claims, err := as.VerifyToken(services.TokenFromRequest(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	client := &Client{
+		hub:  h,
+		conn: conn,
+		send: make(chan []byte, 256),
+		gs: gs,
+		playerID: claims.Subject,
+		lobby: true,
+		mm: mm,
+	}
+	go client.readPump()
+	go client.writePump()
+
+	h.Register <- client
+}
@@ -0,0 +1,79 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/infra"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/memory"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/gorilla/websocket"
+)
+
+// a salvo at every cell of the biggest board must reach the game service instead of
+// closing the connection
+func TestMaxSizeSalvo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := memory.NewMemoryGameRepository()
+	h := NewHub(infra.NewMemoryBroker(), repo)
+	gs := services.NewGameService(repo, h)
+	go h.ListenToSolo(ctx)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := &Client{hub: h, conn: conn, send: make(chan []byte, 256), roomId: "room", gs: gs, playerID: "A"}
+		h.mu.Lock()
+		h.Clients["A"] = client
+		h.mu.Unlock()
+		repo.SetPresence(ctx, "A", h.ServerID)
+
+		go client.writePump()
+		client.readPump()
+	}))
+	defer server.Close()
+	go func() {
+		for range h.Unregister {
+		}
+	}()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var salvo models.SalvoPayload
+	for x := 0; x < domain.MaxBoardSize; x++ {
+		for y := 0; y < domain.MaxBoardSize; y++ {
+			salvo.Shots = append(salvo.Shots, domain.Point{X: x, Y: y})
+		}
+	}
+	payload, _ := json.MarshalIndent(salvo, "", "  ")
+	msg, _ := json.Marshal(models.MessageWs{Type: models.TypeSalvo, Payload: payload})
+	if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	// there is no game in the room, so the salvo is answered with an error
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, reply, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("expected an answer to the salvo, got %v", err)
+	}
+	var answer models.MessageWs
+	if err := json.Unmarshal(reply, &answer); err != nil || answer.Type != models.TypeError {
+		t.Errorf("expected %s, got %s", models.TypeError, reply)
+	}
+}
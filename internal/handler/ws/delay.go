@@ -0,0 +1,93 @@
+package ws
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type delayedMessage struct {
+	at      time.Time
+	payload []byte
+	client  *Client // nil means every spectator of the room
+}
+
+// delayedStream holds spectator messages of a room back for delay before delivering them,
+// the delay is the same for every message so the queue stays in delivery order. The queue
+// has no bound, a spectator missing a shot would see a wrong board for the rest of the game.
+type delayedStream struct {
+	delay time.Duration
+	mu    sync.Mutex
+	queue []delayedMessage
+	// ready is signalled when a message is pushed
+	ready  chan struct{}
+	cancel context.CancelFunc
+}
+
+func newDelayedStream(delay time.Duration) *delayedStream {
+	return &delayedStream{
+		delay: delay,
+		ready: make(chan struct{}, 1),
+	}
+}
+
+// push never blocks, it is called while holding the hub lock
+func (s *delayedStream) push(payload []byte, client *Client) {
+	s.mu.Lock()
+	s.queue = append(s.queue, delayedMessage{at: time.Now().Add(s.delay), payload: payload, client: client})
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// next takes the oldest message off the queue
+func (s *delayedStream) next() (delayedMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return delayedMessage{}, false
+	}
+	msg := s.queue[0]
+	s.queue[0] = delayedMessage{}
+	s.queue = s.queue[1:]
+	return msg, true
+}
+
+func (h *Hub) runDelayedStream(ctx context.Context, roomId string, s *delayedStream) {
+	for {
+		msg, ok := s.next()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.ready:
+			}
+			continue
+		}
+
+		timer := time.NewTimer(time.Until(msg.at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		h.mu.Lock()
+		for client := range h.Rooms[roomId] {
+			if !client.spectator || (msg.client != nil && msg.client != client) {
+				continue
+			}
+			select {
+			case client.send <- msg.payload:
+			default:
+				close(client.send)
+				delete(h.Rooms[roomId], client)
+			}
+		}
+		h.mu.Unlock()
+	}
+}
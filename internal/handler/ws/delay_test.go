@@ -0,0 +1,76 @@
+package ws
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDelayedStream(t *testing.T) {
+	spectator := &Client{send: make(chan []byte, 4), roomId: "room", spectator: true}
+	player := &Client{send: make(chan []byte, 4), roomId: "room"}
+
+	h := &Hub{Rooms: map[string]map[*Client]bool{"room": {spectator: true, player: true}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := newDelayedStream(50 * time.Millisecond)
+	go h.runDelayedStream(ctx, "room", stream)
+
+	start := time.Now()
+	h.mu.Lock()
+	stream.push([]byte("first"), nil)
+	stream.push([]byte("second"), nil)
+	h.mu.Unlock()
+
+	for _, want := range []string{"first", "second"} {
+		select {
+		case got := <-spectator.send:
+			if string(got) != want {
+				t.Fatalf("expected %s but got %s", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s to be delivered", want)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected delivery after the delay but got it after %v", elapsed)
+	}
+
+	if len(player.send) != 0 {
+		t.Fatalf("expected players to get nothing from the spectator stream")
+	}
+}
+
+// a burst of messages is held back in full, none may be dropped
+func TestDelayedStreamBurst(t *testing.T) {
+	const burst = 4096
+	spectator := &Client{send: make(chan []byte, burst), roomId: "room", spectator: true}
+	h := &Hub{Rooms: map[string]map[*Client]bool{"room": {spectator: true}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := newDelayedStream(10 * time.Millisecond)
+	go h.runDelayedStream(ctx, "room", stream)
+
+	h.mu.Lock()
+	for i := range burst {
+		stream.push([]byte(strconv.Itoa(i)), nil)
+	}
+	h.mu.Unlock()
+
+	for i := range burst {
+		select {
+		case got := <-spectator.send:
+			if string(got) != strconv.Itoa(i) {
+				t.Fatalf("expected %d but got %s", i, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected message %d to be delivered", i)
+		}
+	}
+}
@@ -8,25 +8,31 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/infra"
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
 	"github.com/google/uuid"
-	"github.com/redis/go-redis/v9"
 )
 
+const disconnectTimeOut = 30*time.Second
+
 type Hub struct {
 	ServerID	string
 	Rooms	   map[string]map[*Client]bool
 	Clients		map[string]*Client
 	RoomsCancels map[string]context.CancelFunc	
+	SpectatorStreams map[string]*delayedStream
+	Lobby      map[*Client]bool
 	Register   chan *Client
 	Unregister chan *Client
 	Broadcast  chan  models.Message
-	rdb		   *redis.Client
+	broker	   infra.Broker
+	repo	   services.GameRepository
 	mu   			sync.Mutex
 }
 
 
-func NewHub(rbd *redis.Client) *Hub {
+func NewHub(broker infra.Broker, repo services.GameRepository) *Hub {
 	
 	Id := uuid.NewString()
 
@@ -34,23 +40,38 @@ func NewHub(rbd *redis.Client) *Hub {
 		ServerID: Id,
 		Rooms:    make(map[string]map[*Client]bool),
 		RoomsCancels: make(map[string]context.CancelFunc),
+		SpectatorStreams: make(map[string]*delayedStream),
+		Lobby: make(map[*Client]bool),
 		Clients: make(map[string]*Client),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
 		Broadcast:  make(chan models.Message),
-		rdb: rbd,
+		broker: broker,
+		repo: repo,
 	}
 }
 
 func (h *Hub) Run()  {
 
 	go h.ListenToSolo(context.Background())
+	go h.ListenToLobby(context.Background())
 
 	for {
 		select {
 		case client:= <-h.Register:
-			key := "disconnect:"+client.roomId+":"+client.playerID
-			h.rdb.Del(context.Background(),key)
+			if client.lobby {
+				h.mu.Lock()
+				h.Lobby[client] = true
+				h.Clients[client.playerID] = client
+				h.repo.SetPresence(context.Background(),client.playerID,h.ServerID)
+				h.mu.Unlock()
+				log.Println("user : "+client.playerID+" Joined the lobby")
+				continue
+			}
+			if !client.spectator {
+				key := "disconnect:"+client.roomId+":"+client.playerID
+				h.repo.ClearTimeOut(context.Background(),key)
+			}
 			h.mu.Lock()
 			if h.Rooms[client.roomId] == nil {
 				h.Rooms[client.roomId] = make(map[*Client]bool)
@@ -59,13 +80,25 @@ func (h *Hub) Run()  {
 				go h.SubscribeToRoom(ctx,client.roomId)
 			}
 
-			h.rdb.HSet(context.Background(),"presence",client.playerID,h.ServerID) // telling redis which server has which player
+			if client.spectator && client.delay > 0 && h.SpectatorStreams[client.roomId] == nil {
+				stream := newDelayedStream(client.delay)
+				ctx,cancel := context.WithCancel(context.Background())
+				stream.cancel = cancel
+				h.SpectatorStreams[client.roomId] = stream
+				go h.runDelayedStream(ctx,client.roomId,stream)
+			}
+
+			h.repo.SetPresence(context.Background(),client.playerID,h.ServerID) // telling other servers which server has which player
 			h.Rooms[client.roomId][client] = true
 			h.Clients[client.playerID] = client
 			h.mu.Unlock()
 
 			go func() {
-				if err := client.gs.HandleJoin(context.Background(),client.playerID,client.roomId); err!= nil {
+				join := client.gs.HandleJoin
+				if client.spectator {
+					join = client.gs.HandleSpectate
+				}
+				if err := join(context.Background(),client.playerID,client.roomId); err!= nil {
 					log.Println("player got removed due to err : ",err)
 					h.Unregister<-client
 				}
@@ -74,6 +107,12 @@ func (h *Hub) Run()  {
 
 		case client := <-h.Unregister:
 			h.mu.Lock()			
+			if h.Lobby[client] {
+				delete(h.Lobby,client)
+				close(client.send)
+				h.removeClient(client)
+				log.Println("user : "+client.playerID+" Left the lobby")
+			}
 			if clients ,ok := h.Rooms[client.roomId]; ok {
 				if _,ok := clients[client]; ok{
 					delete(clients,client)
@@ -84,11 +123,16 @@ func (h *Hub) Run()  {
 							cancel()
 							delete(h.RoomsCancels,client.roomId)
 						}
+						if stream,ok := h.SpectatorStreams[client.roomId]; ok {
+							stream.cancel()
+							delete(h.SpectatorStreams,client.roomId)
+						}
+					}
+					if !client.spectator {
+						key := "disconnect:"+client.roomId+":"+client.playerID
+						h.repo.SetTimeOut(context.Background(),key,disconnectTimeOut)
 					}
-					key := "disconnect:"+client.roomId+":"+client.playerID
-					h.rdb.Set(context.Background(),key,"Active",30*time.Second)
-					delete(h.Clients,client.playerID)
-					h.rdb.HDel(context.Background(),"presence",client.playerID)
+					h.removeClient(client)
 					log.Println("user : "+client.playerID+" Removed")
 
 				}
@@ -96,25 +140,46 @@ func (h *Hub) Run()  {
 			h.mu.Unlock()
 
 		case message := <-h.Broadcast:
-			err := h.rdb.Publish(context.Background(),message.RoomID,message.Payload).Err()		
+			err := h.broker.Publish(context.Background(),message.RoomID,message.Payload)
 			if err!= nil {
-				log.Printf("Redis publish error : %v", err)
+				log.Printf("publish error : %v", err)
 			}
 		}
 	}
 }
 
+// removeClient forgets the player of client unless it connected again since,
+// a player leaving the lobby for its match keeps the connection to the room. h.mu must be held.
+func (h *Hub) removeClient(client *Client) {
+	if h.Clients[client.playerID] != client {
+		return
+	}
+	delete(h.Clients,client.playerID)
+	h.repo.RemovePresence(context.Background(),client.playerID)
+}
+
+// SubscribeToRoom fans out the room channel to every client of the room
+// and the spectator channel of the room to its spectators only.
 func (h *Hub) SubscribeToRoom(ctx context.Context,roomId string){
 	
-	pubsub := h.rdb.Subscribe(ctx,roomId)
-	defer pubsub.Close()
-	
-	ch := pubsub.Channel()
+	ch := h.broker.Subscribe(ctx,roomId,spectatorChannel(roomId))
 	for msg := range ch {
+		spectatorsOnly := msg.Channel == spectatorChannel(roomId)
 		h.mu.Lock()
+		// spectators of a delayed room get the message later from the delayed stream
+		stream := h.SpectatorStreams[roomId]
+		if stream != nil {
+			stream.push(msg.Payload,nil)
+		}
 		for client := range h.Rooms[roomId] {
+			if spectatorsOnly && !client.spectator {
+				continue
+			}
+			if client.spectator && stream != nil {
+				continue
+			}
 			select{
-			case client.send <- []byte(msg.Payload):
+			case client.send <- msg.Payload:
 			default:
 				close(client.send)
 				delete(h.Rooms[roomId],client)
@@ -125,17 +190,18 @@ func (h *Hub) SubscribeToRoom(ctx context.Context,roomId string){
 }
 
 func (h *Hub) ListenToSolo(ctx context.Context)  {
-	pubsub := h.rdb.PSubscribe(ctx,fmt.Sprintf("solo:%s:*",h.ServerID))
-	defer pubsub.Close()
-
-	ch := pubsub.Channel()
+	ch := h.broker.PSubscribe(ctx,fmt.Sprintf("solo:%s:*",h.ServerID))
 
 	for message := range ch {
 		parts := strings.Split(message.Channel, ":")
 		playerID := parts[2]
 		h.mu.Lock()
 		if client, ok := h.Clients[playerID]; ok {
-			client.send <- []byte(message.Payload)
+			if stream := h.SpectatorStreams[client.roomId]; client.spectator && stream != nil {
+				stream.push(message.Payload,client)
+			} else {
+				client.send <- message.Payload
+			}
 		}
 		h.mu.Unlock()
 	}
@@ -150,7 +216,46 @@ func (h *Hub) BroadcastMessage(roomID string, payload []byte)  {
 }
 
 func (h *Hub) SoloMessage(channel string,payload []byte)  {
-	if err := h.rdb.Publish(context.Background(),channel,payload).Err(); err!= nil {
-		log.Printf("Redis publish error : %v",err)
+	if err := h.broker.Publish(context.Background(),channel,payload); err!= nil {
+		log.Printf("publish error : %v",err)
 	}
 }
+
+func (h *Hub) SpectatorMessage(roomID string, payload []byte) {
+	if err := h.broker.Publish(context.Background(),spectatorChannel(roomID),payload); err!= nil {
+		log.Printf("publish error : %v",err)
+	}
+}
+
+// ListenToLobby fans out the lobby channel to the lobby connections of this server,
+// a lobby connection that can not keep up is dropped
+func (h *Hub) ListenToLobby(ctx context.Context)  {
+	ch := h.broker.Subscribe(ctx,lobbyChannel)
+
+	for message := range ch {
+		h.mu.Lock()
+		for client := range h.Lobby {
+			select{
+			case client.send <- message.Payload:
+			default:
+				close(client.send)
+				delete(h.Lobby,client)
+				h.removeClient(client)
+			}
+		}
+		h.mu.Unlock()
+	}
+}
+
+func (h *Hub) LobbyMessage(payload []byte)  {
+	if err := h.broker.Publish(context.Background(),lobbyChannel,payload); err!= nil {
+		log.Printf("publish error : %v",err)
+	}
+}
+
+// lobbyChannel carries the changes of public rooms to every server
+const lobbyChannel = "lobby"
+
+func spectatorChannel(roomID string) string {
+	return "spectate:"+roomID
+}
@@ -0,0 +1,100 @@
+package ws
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	minReplaySpeed = 0.25
+	maxReplaySpeed = 32
+)
+
+// ServeReplay re-streams a finished game to a single connection, the gaps between
+// messages follow the original game divided by speed (?speed=4 plays four times faster).
+func ServeReplay(gs *services.GameService, upgrader websocket.Upgrader, w http.ResponseWriter, r *http.Request) {
+	gameID := r.URL.Query().Get("gameID")
+	if gameID == "" {
+		http.Error(w, "gameID is missing", http.StatusBadRequest)
+		return
+	}
+
+	speed, err := strconv.ParseFloat(r.URL.Query().Get("speed"), 64)
+	if err != nil {
+		speed = 1
+	}
+	speed = min(max(speed, minReplaySpeed), maxReplaySpeed)
+
+	round, _ := strconv.Atoi(r.URL.Query().Get("round"))
+
+	frames, err := gs.ReplayFrames(r.Context(), gameID, round)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer conn.Close()
+
+	// the client only listens, reading is needed to notice that it went away
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// tell the client that what follows is a replay and not a live game
+	p, _ := json.Marshal(models.ReplayStartPayload{
+		GameID: gameID,
+		Speed:  speed,
+		Frames: len(frames),
+	})
+	start, _ := json.Marshal(models.MessageWs{
+		Type:    models.TypeReplay,
+		Payload: json.RawMessage(p),
+	})
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := conn.WriteMessage(websocket.TextMessage, start); err != nil {
+		return
+	}
+
+	for i, f := range frames {
+		if i > 0 {
+			wait := time.Duration(float64(time.Duration(f.At-frames[i-1].At)*time.Millisecond) / speed)
+			select {
+			case <-done:
+				return
+			case <-time.After(wait):
+			}
+		}
+
+		msg, err := json.Marshal(f.Message)
+		if err != nil {
+			log.Printf("failed to marshal response :%v", f.Message)
+			continue
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+			return
+		}
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "replay finished"))
+}
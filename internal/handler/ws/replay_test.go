@@ -0,0 +1,18 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestReplayWithoutGameID(t *testing.T) {
+	w := httptest.NewRecorder()
+	ServeReplay(nil, websocket.Upgrader{}, w, httptest.NewRequest(http.MethodGet, "/ws?mode=replay", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -3,6 +3,7 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
@@ -10,20 +11,37 @@ import (
 )
 
 
-func MessageHandler(raw []byte,roomId string,gs *services.GameService, clientID string) error {
+var ErrSpectatorReadOnly = errors.New("spectators can not send messages to the room")
+
+func MessageHandler(raw []byte,roomId string,gs *services.GameService, clientID string, spectator bool) error {
 	var msg models.MessageWs;
 	
 	if err := json.Unmarshal(raw,&msg); err!=nil {
 		return err
 	}
+
+	// spectators only watch, chat is blocked too so the audience can not talk to the players
+	if spectator {
+		return ErrSpectatorReadOnly
+	}
 	
 	switch msg.Type {
 	case models.TypeMove:
 		gs.HandleMove(context.Background(),clientID,roomId,msg.Payload)
+	case models.TypeSalvo:
+		gs.HandleSalvo(context.Background(),clientID,roomId,msg.Payload)
 	case models.TypePlaceShip:
 		gs.HandlePlace(context.Background(),clientID,roomId,msg.Payload)
+	case models.TypeRematchRequest:
+		gs.HandleRematchRequest(context.Background(),clientID,roomId)
+	case models.TypeRematchAccept:
+		gs.HandleRematchAccept(context.Background(),clientID,roomId)
 	case models.TypeChat:
 		gs.HandleChat(context.Background(),clientID,roomId,msg.Payload)
+	case models.TypeChatMute:
+		gs.HandleChatMute(context.Background(),clientID,roomId,msg.Payload)
+	case models.TypeChatReport:
+		gs.HandleChatReport(context.Background(),clientID,roomId,msg.Payload)
 	default:
 		log.Println("Invalid Type of Message")
 	}
@@ -31,3 +49,21 @@ func MessageHandler(raw []byte,roomId string,gs *services.GameService, clientID
 	return nil
 }
 
+
+// LobbyMessageHandler handles the messages of a lobby connection, only matchmaking is possible there
+func LobbyMessageHandler(raw []byte, mm *services.Matchmaker, clientID string) {
+	var msg models.MessageWs
+	if err := json.Unmarshal(raw,&msg); err!=nil {
+		log.Print(err)
+		return
+	}
+
+	switch msg.Type {
+	case models.TypeQueue:
+		mm.HandleQueue(context.Background(),clientID,msg.Payload)
+	case models.TypeQueueLeave:
+		mm.HandleQueueLeave(context.Background(),clientID)
+	default:
+		log.Println("Invalid Type of Message in lobby")
+	}
+}
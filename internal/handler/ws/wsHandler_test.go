@@ -0,0 +1,44 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/infra"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/memory"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/services"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// a spectator connection never reaches the game, even with the id of the player on turn
+func TestSpectatorMove(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryGameRepository()
+	h := NewHub(infra.NewMemoryBroker(), repo)
+	gs := services.NewGameService(repo, h)
+
+	game := domain.NewGame("A", "B", "room", domain.DefaultRuleSet())
+	game.Status = domain.StatusActive
+	game.ActivePlayer = "A"
+	if err := repo.SaveGame(ctx, game); err != nil {
+		t.Fatal(err)
+	}
+	version := game.Version
+
+	payload, _ := json.Marshal(models.MovePayload{X: 0, Y: 0})
+	move, _ := json.Marshal(models.MessageWs{Type: models.TypeMove, Payload: payload})
+	if err := MessageHandler(move, "room", gs, "A", true); err != ErrSpectatorReadOnly {
+		t.Fatalf("spectator move: expected %v, got %v", ErrSpectatorReadOnly, err)
+	}
+
+	game, err := repo.GetGame(ctx, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Version != version || game.ActivePlayer != "A" || game.Boards["B"][0][0] != domain.Empty {
+		t.Errorf("game after a spectator move: expected it unchanged, got version %d, %s on turn, cell %v",
+			game.Version, game.ActivePlayer, game.Boards["B"][0][0])
+	}
+}
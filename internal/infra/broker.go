@@ -0,0 +1,68 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BrokerMessage is a message received on a channel of the broker
+type BrokerMessage struct {
+	Channel string
+	Payload []byte
+}
+
+// Broker carries messages between servers, Subscribe and PSubscribe return a
+// channel that is closed once ctx is done. Patterns only support a trailing "*".
+type Broker interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+	Subscribe(ctx context.Context, channels ...string) <-chan BrokerMessage
+	PSubscribe(ctx context.Context, pattern string) <-chan BrokerMessage
+}
+
+// RedisBroker uses Redis pub/sub so every server of the cluster gets the messages
+type RedisBroker struct {
+	rdb *redis.Client
+}
+
+func NewRedisBroker(rdb *redis.Client) *RedisBroker {
+	return &RedisBroker{rdb: rdb}
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, channel string, payload []byte) error {
+	return b.rdb.Publish(ctx, channel, payload).Err()
+}
+
+func (b *RedisBroker) Subscribe(ctx context.Context, channels ...string) <-chan BrokerMessage {
+	return forward(ctx, b.rdb.Subscribe(ctx, channels...))
+}
+
+func (b *RedisBroker) PSubscribe(ctx context.Context, pattern string) <-chan BrokerMessage {
+	return forward(ctx, b.rdb.PSubscribe(ctx, pattern))
+}
+
+func forward(ctx context.Context, pubsub *redis.PubSub) <-chan BrokerMessage {
+	out := make(chan BrokerMessage)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- BrokerMessage{Channel: msg.Channel, Payload: []byte(msg.Payload)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
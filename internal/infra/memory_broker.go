@@ -0,0 +1,102 @@
+package infra
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+type subscription struct {
+	mu       sync.Mutex
+	closed   bool
+	channels map[string]bool
+	prefix   string
+	out      chan BrokerMessage
+	done     <-chan struct{}
+}
+
+func (s *subscription) matches(channel string) bool {
+	if s.channels != nil {
+		return s.channels[channel]
+	}
+	return strings.HasPrefix(channel, s.prefix)
+}
+
+// MemoryBroker delivers messages inside the process, it is enough for a single server
+type MemoryBroker struct {
+	mu   sync.Mutex
+	subs map[*subscription]bool
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: make(map[*subscription]bool)}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, channel string, payload []byte) error {
+	b.mu.Lock()
+	targets := []*subscription{}
+	for s := range b.subs {
+		if s.matches(channel) {
+			targets = append(targets, s)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, s := range targets {
+		if err := s.deliver(ctx, BrokerMessage{Channel: channel, Payload: payload}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *subscription) deliver(ctx context.Context, msg BrokerMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	select {
+	case s.out <- msg:
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, channels ...string) <-chan BrokerMessage {
+	set := make(map[string]bool, len(channels))
+	for _, c := range channels {
+		set[c] = true
+	}
+	return b.add(ctx, &subscription{channels: set})
+}
+
+func (b *MemoryBroker) PSubscribe(ctx context.Context, pattern string) <-chan BrokerMessage {
+	return b.add(ctx, &subscription{prefix: strings.TrimSuffix(pattern, "*")})
+}
+
+func (b *MemoryBroker) add(ctx context.Context, s *subscription) <-chan BrokerMessage {
+	s.out = make(chan BrokerMessage, 256)
+	s.done = ctx.Done()
+
+	b.mu.Lock()
+	b.subs[s] = true
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subs, s)
+		b.mu.Unlock()
+
+		// a publisher blocked on out gives up once done is closed, after that out can be closed
+		s.mu.Lock()
+		s.closed = true
+		close(s.out)
+		s.mu.Unlock()
+	}()
+	return s.out
+}
@@ -0,0 +1,39 @@
+package models
+
+import "github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+
+// SessionRequest starts a session, users log in with their username and password
+// and guests may pick a display name
+type SessionRequest struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type SessionResponse struct {
+	Token     string `json:"token"`
+	PlayerID  string `json:"playerID"`
+	Name      string `json:"name,omitempty"`
+	Guest     bool   `json:"guest"`
+	ExpiresAt int64  `json:"expiresAt"`
+	// User is the profile of a registered user
+	User *domain.User `json:"user,omitempty"`
+}
+
+type RegisterRequest struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	DisplayName string `json:"displayName"`
+	AvatarURL   string `json:"avatarURL"`
+}
+
+// ProfileRequest changes the profile, fields left out keep their value
+type ProfileRequest struct {
+	DisplayName *string `json:"displayName"`
+	AvatarURL   *string `json:"avatarURL"`
+}
+
+type RatingResponse struct {
+	PlayerID string `json:"playerID"`
+	domain.Rating
+}
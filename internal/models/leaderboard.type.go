@@ -0,0 +1,13 @@
+package models
+
+import "github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+
+// LeaderboardResponse is a page of a board, Season is empty for the global boards
+// and Me is the place of the caller when it is on the board
+type LeaderboardResponse struct {
+	Board   domain.Board              `json:"board"`
+	Season  *domain.Season            `json:"season,omitempty"`
+	Total   int64                     `json:"total"`
+	Entries []domain.LeaderboardEntry `json:"entries"`
+	Me      *domain.LeaderboardEntry  `json:"me,omitempty"`
+}
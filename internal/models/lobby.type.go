@@ -0,0 +1,38 @@
+package models
+
+import "github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+
+// LobbyEvent says what happened to a public room
+type LobbyEvent string
+
+const (
+	LobbyOpened  LobbyEvent = "OPENED"
+	LobbyUpdated LobbyEvent = "UPDATED"
+	LobbyFilled  LobbyEvent = "FILLED"
+	LobbyStarted LobbyEvent = "STARTED"
+	LobbyClosed  LobbyEvent = "CLOSED"
+)
+
+// LobbyRoom is a public room as the lobby lists it, Players counts the seated players
+type LobbyRoom struct {
+	ID        string            `json:"id"`
+	Host      string            `json:"host,omitempty"`
+	Rules     domain.RuleSet    `json:"rules"`
+	Status    domain.RoomStatus `json:"status"`
+	Players   int               `json:"players"`
+	BestOf    int               `json:"bestOf"`
+	CreatedAt int64             `json:"createdAt"`
+}
+
+type LobbyResponse struct {
+	Rooms []LobbyRoom `json:"rooms"`
+	Total int64       `json:"total"`
+}
+
+// LobbyUpdatePayload is pushed to lobby connections when a public room changes,
+// Room is left out once the room is closed
+type LobbyUpdatePayload struct {
+	Event  LobbyEvent `json:"event"`
+	RoomID string     `json:"roomID"`
+	Room   *LobbyRoom `json:"room,omitempty"`
+}
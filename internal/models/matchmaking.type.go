@@ -0,0 +1,26 @@
+package models
+
+import "github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+
+// MatchmakingSettings are the parameters the matchmaker runs with, durations in milliseconds
+type MatchmakingSettings struct {
+	Window        int    `json:"window"`
+	WindowGrowth  int    `json:"windowGrowth"`
+	MaxWindow     int    `json:"maxWindow"`
+	RegionWaitMs  int64  `json:"regionWaitMs"`
+	MaxWaitMs     int64  `json:"maxWaitMs"`
+	BotDifficulty string `json:"botDifficulty,omitempty"`
+}
+
+// QueuedPlayer is a waiting player with the rating window it accepts right now
+type QueuedPlayer struct {
+	domain.QueueEntry
+	WaitedMs int64 `json:"waitedMs"`
+	Window   int   `json:"window"`
+}
+
+// QueueStatusResponse shows the matchmaking queue to admins tuning the settings
+type QueueStatusResponse struct {
+	Settings MatchmakingSettings `json:"settings"`
+	Players  []QueuedPlayer      `json:"players"`
+}
@@ -23,6 +23,21 @@ const (
 	TypePlaceShip MessageType = "PLACE_SHIP"
 	TypeGameUpdate MessageType = "GAME_UPDATE"
 	TypeTimeOut MessageType = "TIME_OUT"
+	TypeShipSunk MessageType = "SHIP_SUNK"
+	TypeSalvo MessageType = "SALVO"
+	TypeSpectatorState MessageType = "SPECTATOR_STATE"
+	TypeReplay MessageType = "REPLAY"
+	TypeRematchRequest MessageType = "REMATCH_REQUEST"
+	TypeRematchAccept MessageType = "REMATCH_ACCEPT"
+	TypeSeriesUpdate MessageType = "SERIES_UPDATE"
+	TypeChatHistory MessageType = "CHAT_HISTORY"
+	TypeChatMute MessageType = "CHAT_MUTE"
+	TypeChatReport MessageType = "CHAT_REPORT"
+	TypeQueue MessageType = "QUEUE"
+	TypeQueueLeave MessageType = "QUEUE_LEAVE"
+	TypeMatchFound MessageType = "MATCH_FOUND"
+	TypeTournamentUpdate MessageType = "TOURNAMENT_UPDATE"
+	TypeLobbyUpdate MessageType = "LOBBY_UPDATE"
 
 )
 
@@ -42,8 +57,20 @@ type MovePayload struct {
 	Y int `json:"y"`
 }
 
+// GameOverPayload ends a game, Ratings holds the new rating of both players of a ranked game
 type GameOverPayload struct {
-	Winner string `json:"winner"`
+	Winner  string                  `json:"winner"`
+	Ratings map[string]RatingChange `json:"ratings,omitempty"`
+}
+
+type RatingChange struct {
+	Rating int `json:"rating"`
+	Delta  int `json:"delta"`
+}
+
+type RematchPayload struct {
+	By    string `json:"by"`
+	Round int    `json:"round"`
 }
 
 type ErrorPayload struct {
@@ -60,8 +87,28 @@ type HitPayload struct {
 
 }
 
+type SalvoPayload struct {
+	Shots []domain.Point `json:"shots"`
+}
+
+type SalvoResultPayload struct {
+	Shots     []domain.ShotResult `json:"shots"`
+	Sunk      []domain.PlacedShip `json:"sunk"`
+	NextTurn  string              `json:"nextTurn"`
+	NextShots int                 `json:"nextShots"`
+	By        string              `json:"by"`
+	EndAt     int64               `json:"endAt"`
+}
+
+type ShipSunkPayload struct {
+	Owner string `json:"owner"`
+	By    string `json:"by"`
+	Name  string `json:"name"`
+	Cells []domain.Point `json:"cells"`
+}
+
 type PlacePayload struct {
-	Ships []domain.Point `json:"ships"`
+	Ships []domain.ShipPlacement `json:"ships"`
 }
 
 type UpdatePayload struct {
@@ -72,13 +119,95 @@ type GameStateResponse struct {
 	Id string `json:"id"`
 	YourBoard [][]domain.CellState `json:"yourBoard"`
 	OpponentBoard [][]domain.CellState `json:"opponentBoard"`
+	OpponentSunk []domain.PlacedShip `json:"opponentSunk"`
 	ActivePlayer string	`json:"activePlayer"`
 	Winner	string	`json:"winner"`
 	Status	domain.GameStatus	`json:"status"` 
 	EndAt int64 `json:"endAt"`
+	Rules domain.RuleSet `json:"rules"`
+	ShotsPerTurn int `json:"shotsPerTurn"`
+	Round int `json:"round"`
+	Series *domain.Series `json:"series,omitempty"`
+}
+
+// SpectatorStateResponse shows both boards with hits and misses only
+type SpectatorStateResponse struct {
+	Id           string                          `json:"id"`
+	Players      [2]string                       `json:"players"`
+	Boards       map[string][][]domain.CellState `json:"boards"`
+	Sunk         map[string][]domain.PlacedShip  `json:"sunk"`
+	ActivePlayer string                          `json:"activePlayer"`
+	Winner       string                          `json:"winner"`
+	Status       domain.GameStatus               `json:"status"`
+	EndAt        int64                           `json:"endAt"`
+	Rules        domain.RuleSet                  `json:"rules"`
 }
 
 type ChatPayload struct {
 	Sender string `json:"sender,omitempty"`
 	Message string `json:"message"`
-}
\ No newline at end of file
+}
+
+// ChatMutePayload mutes or unmutes the chat of Player for the sender only
+type ChatMutePayload struct {
+	Player string `json:"player"`
+	Muted  bool   `json:"muted"`
+}
+
+// ChatReportPayload reports the chat message MessageID to the moderators
+type ChatReportPayload struct {
+	MessageID string `json:"messageID"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ChatHistoryPayload holds chat messages of a room, oldest first. NextBefore is
+// the before to ask for the page of older messages, it is empty on the oldest page.
+type ChatHistoryPayload struct {
+	Messages   []domain.ChatMessage `json:"messages"`
+	NextBefore string               `json:"nextBefore,omitempty"`
+}
+
+// ReplayFrame is one message of a replayed game, At is when it happened in the original game
+type ReplayFrame struct {
+	At      int64     `json:"at"`
+	Message MessageWs `json:"message"`
+}
+
+type ReplayStartPayload struct {
+	GameID string  `json:"gameID"`
+	Speed  float64 `json:"speed"`
+	Frames int     `json:"frames"`
+}
+
+type ReplayResponse struct {
+	GameID  string         `json:"gameID"`
+	Round   int            `json:"round"`
+	Players [2]string      `json:"players"`
+	Winner  string         `json:"winner"`
+	Rules   domain.RuleSet `json:"rules"`
+	Events  []domain.Event `json:"events"`
+}
+
+// QueueRequest is the optional payload of QUEUE, players are only paired with their
+// region until they waited long enough, no region plays everywhere
+type QueueRequest struct {
+	Region string `json:"region"`
+}
+
+// QueuePayload answers QUEUE and QUEUE_LEAVE with the place of the player in the matchmaking queue,
+// Reason says why the server took a player out of the queue
+type QueuePayload struct {
+	Queued     bool   `json:"queued"`
+	EnqueuedAt int64  `json:"enqueuedAt,omitempty"`
+	Rating     int    `json:"rating,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// MatchFoundPayload tells a queued player the room to join for its match,
+// Bot is set when nobody was found in time and the opponent is the AI
+type MatchFoundPayload struct {
+	RoomID   string `json:"roomID"`
+	Opponent string `json:"opponent"`
+	Bot      bool   `json:"bot,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+
+const OpponentBot = "bot"
+
+type CreateRoomRequest struct {
+	Rules      domain.RuleSet `json:"rules"`
+	Opponent   string         `json:"opponent"`
+	Difficulty string         `json:"difficulty"`
+	BestOf     int            `json:"bestOf"`
+	Creator    string         `json:"-"` // from the session of the caller, never from the body
+	Visibility string         `json:"visibility"`
+	Seats      []string       `json:"-"` // players the server reserved the room for
+}
+
+type RoomResponse struct {
+	domain.Room
+	Players []string       `json:"players"`
+	Series  *domain.Series `json:"series,omitempty"`
+}
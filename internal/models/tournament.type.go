@@ -0,0 +1,23 @@
+package models
+
+import "github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+
+// CreateTournamentRequest opens a tournament for registration, maxPlayers 0 takes the
+// most players possible and bestOf 0 plays single games
+type CreateTournamentRequest struct {
+	Name       string         `json:"name"`
+	Format     string         `json:"format"`
+	MaxPlayers int            `json:"maxPlayers"`
+	Rules      domain.RuleSet `json:"rules"`
+	BestOf     int            `json:"bestOf"`
+}
+
+// RegisterPlayerRequest registers the caller, or another player when the organizer sends it
+type RegisterPlayerRequest struct {
+	PlayerID string `json:"playerID"`
+}
+
+// ReportMatchRequest lets the organizer decide a match that ended without a winner
+type ReportMatchRequest struct {
+	Winner string `json:"winner"`
+}
@@ -0,0 +1,32 @@
+package repository
+
+// AdmitStatus is the outcome of adding a player to one of the two seat sets of a game,
+// the players of the game and the players that placed their ships.
+type AdmitStatus int
+
+const (
+	Admitted AdmitStatus = iota
+	AlreadyPresent
+	Full
+)
+
+func (s AdmitStatus) String() string {
+	switch s {
+	case Admitted:
+		return "admitted"
+	case AlreadyPresent:
+		return "already-present"
+	case Full:
+		return "full"
+	}
+	return "unknown"
+}
+
+// AdmitResult tells what happened to the player and how many players the set holds afterwards
+type AdmitResult struct {
+	Status AdmitStatus
+	Count  int64
+}
+
+// SeatsPerGame is how many players fit in a game
+const SeatsPerGame = 2
@@ -0,0 +1,170 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	usersBucket     = []byte("users")
+	usernamesBucket = []byte("usernames")
+)
+
+// userRecord is how a user is stored, unlike domain.User it keeps the password hash in JSON
+type userRecord struct {
+	ID           string        `json:"id"`
+	Username     string        `json:"username"`
+	DisplayName  string        `json:"displayName"`
+	AvatarURL    string        `json:"avatarURL,omitempty"`
+	PasswordHash []byte        `json:"passwordHash"`
+	CreatedAt    int64         `json:"createdAt"`
+	Rating       domain.Rating `json:"rating"`
+}
+
+// BoltUserRepository keeps registered users in a local bolt file, it is meant for
+// development and single servers, a cluster shares users through MongoDB.
+type BoltUserRepository struct {
+	db *bolt.DB
+}
+
+// NewBoltUserRepository opens or creates the bolt file at path
+func NewBoltUserRepository(path string) (*BoltUserRepository, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{usersBucket, usernamesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltUserRepository{db: db}, nil
+}
+
+func (R *BoltUserRepository) Close() error {
+	return R.db.Close()
+}
+
+func (R *BoltUserRepository) CreateUser(ctx context.Context, u *domain.User) error {
+	return R.db.Update(func(tx *bolt.Tx) error {
+		usernames := tx.Bucket(usernamesBucket)
+		if usernames.Get([]byte(u.Username)) != nil {
+			return domain.ErrUsernameTaken
+		}
+		if err := usernames.Put([]byte(u.Username), []byte(u.ID)); err != nil {
+			return err
+		}
+		return putUser(tx, u)
+	})
+}
+
+func (R *BoltUserRepository) GetUser(ctx context.Context, id string) (*domain.User, error) {
+	var u *domain.User
+	err := R.db.View(func(tx *bolt.Tx) error {
+		var err error
+		u, err = getUser(tx, id)
+		return err
+	})
+	return u, err
+}
+
+func (R *BoltUserRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	var u *domain.User
+	err := R.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(usernamesBucket).Get([]byte(username))
+		if id == nil {
+			return domain.ErrUserNotFound
+		}
+		var err error
+		u, err = getUser(tx, string(id))
+		return err
+	})
+	return u, err
+}
+
+func (R *BoltUserRepository) UpdateProfile(ctx context.Context, id string, displayName string, avatarURL string) error {
+	return R.db.Update(func(tx *bolt.Tx) error {
+		u, err := getUser(tx, id)
+		if err != nil {
+			return err
+		}
+		u.DisplayName = displayName
+		u.AvatarURL = avatarURL
+		return putUser(tx, u)
+	})
+}
+
+// AddRatingResult counts a ranked game of the user, bolt runs one write transaction at a time
+func (R *BoltUserRepository) AddRatingResult(ctx context.Context, userID string, delta int, won bool) (domain.Rating, error) {
+	var rating domain.Rating
+	err := R.db.Update(func(tx *bolt.Tx) error {
+		u, err := getUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		u.Rating.Add(delta, won)
+		rating = u.Rating
+		return putUser(tx, u)
+	})
+	return rating, err
+}
+
+func putUser(tx *bolt.Tx, u *domain.User) error {
+	data, err := json.Marshal(userRecord(*u))
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(usersBucket).Put([]byte(u.ID), data)
+}
+
+func getUser(tx *bolt.Tx, id string) (*domain.User, error) {
+	data := tx.Bucket(usersBucket).Get([]byte(id))
+	if data == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	var record userRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	u := domain.User(record)
+	return &u, nil
+}
+
+// SoftResetRatings pulls every rating towards the default in one transaction
+func (R *BoltUserRepository) SoftResetRatings(ctx context.Context) error {
+	return R.db.Update(func(tx *bolt.Tx) error {
+		var users []*domain.User
+		err := tx.Bucket(usersBucket).ForEach(func(k, v []byte) error {
+			u, err := getUser(tx, string(k))
+			if err != nil {
+				return err
+			}
+			users = append(users, u)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, u := range users {
+			u.Rating = u.Rating.SoftReset()
+			if err := putUser(tx, u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,18 @@
+package boltdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/repotest"
+)
+
+func TestUsers(t *testing.T) {
+	repo, err := NewBoltUserRepository(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	repotest.RunUsers(t, repo)
+}
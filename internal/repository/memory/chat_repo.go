@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// SaveChat appends msg to the chat of the room and fills in its ID,
+// the last domain.ChatHistoryLimit messages are kept for ttl like the room.
+func (M *MemoryGameRepository) SaveChat(ctx context.Context, roomID string, msg *domain.ChatMessage, ttl time.Duration) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	M.seq++
+	msg.ID = fmt.Sprintf("%d-%d", msg.SentAt, M.seq)
+
+	key := "chat:room-" + roomID
+	messages, _ := M.values[key].([]domain.ChatMessage)
+	messages = append(messages, *msg)
+	if len(messages) > domain.ChatHistoryLimit {
+		messages = messages[len(messages)-domain.ChatHistoryLimit:]
+	}
+	M.set(key, messages, ttl)
+	return nil
+}
+
+// GetChat returns up to limit messages of the room sent before the message with ID before,
+// oldest first. An empty before returns the latest messages.
+func (M *MemoryGameRepository) GetChat(ctx context.Context, roomID string, before string, limit int64) ([]domain.ChatMessage, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	messages, _ := M.values["chat:room-"+roomID].([]domain.ChatMessage)
+	end := len(messages)
+	if before != "" {
+		end = 0
+		for end < len(messages) && idBefore(messages[end].ID, before) {
+			end++
+		}
+	}
+	start := max(end-int(limit), 0)
+	return append([]domain.ChatMessage{}, messages[start:end]...), nil
+}
+
+// idBefore compares two IDs of the form <milliseconds>-<sequence> like Redis stream IDs
+func idBefore(id string, other string) bool {
+	var ms, seq, otherMs, otherSeq int64
+	fmt.Sscanf(id, "%d-%d", &ms, &seq)
+	fmt.Sscanf(other, "%d-%d", &otherMs, &otherSeq)
+	return ms < otherMs || ms == otherMs && seq < otherSeq
+}
+
+// GetChatMessage returns the message with ID id from the chat of the room
+func (M *MemoryGameRepository) GetChatMessage(ctx context.Context, roomID string, id string) (*domain.ChatMessage, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	messages, _ := M.values["chat:room-"+roomID].([]domain.ChatMessage)
+	for _, msg := range messages {
+		if msg.ID == id {
+			return &msg, nil
+		}
+	}
+	return nil, domain.ErrChatNotFound
+}
+
+// CountChat counts a message of playerID and returns how many the player sent in the current window
+func (M *MemoryGameRepository) CountChat(ctx context.Context, roomID string, playerID string) (int64, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	key := "chat:rate:" + roomID + ":" + playerID
+	count, ok := M.values[key].(int64)
+	count++
+	if !ok {
+		// the window starts with the first message
+		M.set(key, count, domain.ChatRateWindow)
+	} else {
+		M.values[key] = count
+	}
+	return count, nil
+}
+
+// SetChatMute mutes or unmutes target for playerID in the room, the mute lives for ttl like the room
+func (M *MemoryGameRepository) SetChatMute(ctx context.Context, roomID string, playerID string, target string, muted bool, ttl time.Duration) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	key := "chat:mute:" + roomID + ":" + playerID
+	if !muted {
+		delete(M.members(key, false), target)
+		return nil
+	}
+	M.members(key, true)[target] = true
+	M.expire(key, ttl)
+	return nil
+}
+
+// GetChatMutes returns the players playerID muted in the room
+func (M *MemoryGameRepository) GetChatMutes(ctx context.Context, roomID string, playerID string) ([]string, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	muted := []string{}
+	for target := range M.members("chat:mute:"+roomID+":"+playerID, false) {
+		muted = append(muted, target)
+	}
+	return muted, nil
+}
+
+// SaveChatReport files report for moderators and fills in its ID, reports do not expire
+func (M *MemoryGameRepository) SaveChatReport(ctx context.Context, report *domain.ChatReport) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	M.seq++
+	report.ID = fmt.Sprintf("%d-%d", report.At, M.seq)
+
+	reports, _ := M.values["chat:reports"].([]domain.ChatReport)
+	M.values["chat:reports"] = append(reports, *report)
+	return nil
+}
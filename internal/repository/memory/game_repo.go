@@ -0,0 +1,434 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+var ErrGameNotFound = errors.New("Game not found")
+
+// MemoryGameRepository keeps everything in the process, it uses the same keys and
+// expiry times as the Redis repository so a single server can run without Redis.
+// Expired keys are reported on Expired just like Redis keyspace events.
+type MemoryGameRepository struct {
+	mu     sync.Mutex
+	values map[string]any
+	timers map[string]*time.Timer
+	// deadlines tells when the keys with a timer expire
+	deadlines map[string]time.Time
+	expired   chan string
+	seq       int64
+}
+
+func NewMemoryGameRepository() *MemoryGameRepository {
+	return &MemoryGameRepository{
+		values:    make(map[string]any),
+		timers:    make(map[string]*time.Timer),
+		deadlines: make(map[string]time.Time),
+		expired:   make(chan string, 64),
+	}
+}
+
+// Expired streams the keys whose time ran out
+func (M *MemoryGameRepository) Expired() <-chan string {
+	return M.expired
+}
+
+// set stores value under key, ttl 0 keeps the key until it is deleted. M.mu must be held.
+func (M *MemoryGameRepository) set(key string, value any, ttl time.Duration) {
+	M.values[key] = value
+	M.expire(key, ttl)
+}
+
+// expire (re)starts the expiry timer of key. M.mu must be held.
+func (M *MemoryGameRepository) expire(key string, ttl time.Duration) {
+	if t, ok := M.timers[key]; ok {
+		t.Stop()
+		delete(M.timers, key)
+		delete(M.deadlines, key)
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(ttl, func() {
+		M.mu.Lock()
+		// the key may have been set again since this timer started
+		if M.timers[key] != t {
+			M.mu.Unlock()
+			return
+		}
+		delete(M.timers, key)
+		delete(M.deadlines, key)
+		delete(M.values, key)
+		M.mu.Unlock()
+
+		M.expired <- key
+	})
+	M.timers[key] = t
+	M.deadlines[key] = time.Now().Add(ttl)
+}
+
+// del removes keys and their timers without reporting them as expired. M.mu must be held.
+func (M *MemoryGameRepository) del(keys ...string) {
+	for _, key := range keys {
+		M.expire(key, 0)
+		delete(M.values, key)
+	}
+}
+
+// members returns the set stored under key, creating it when create is true. M.mu must be held.
+func (M *MemoryGameRepository) members(key string, create bool) map[string]bool {
+	set, ok := M.values[key].(map[string]bool)
+	if !ok && create {
+		set = make(map[string]bool)
+		M.values[key] = set
+	}
+	return set
+}
+
+// SaveGame stores g only when the stored snapshot still has g.Version and appends events
+// with it, a missing snapshot accepts any version.
+func (M *MemoryGameRepository) SaveGame(ctx context.Context, g *domain.Game, events ...domain.Event) error {
+	key := "game:" + g.ID
+	expected := g.Version
+
+	// store a copy so callers can keep changing their game without a save
+	next := *g
+	next.Version = expected + 1
+	data, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	if current, ok := M.values[key].([]byte); ok {
+		var stored struct {
+			Version int64 `json:"version"`
+		}
+		if err := json.Unmarshal(current, &stored); err != nil {
+			return err
+		}
+		if stored.Version != expected {
+			return domain.ErrVersionConflict
+		}
+	}
+
+	M.set(key, data, g.Rules.GameTTL())
+	for _, e := range events {
+		M.appendEvent(g.ID, e)
+	}
+	g.Version = next.Version
+	return nil
+}
+
+func (M *MemoryGameRepository) GetGame(ctx context.Context, id string) (*domain.Game, error) {
+	M.mu.Lock()
+	data, ok := M.values["game:"+id].([]byte)
+	M.mu.Unlock()
+
+	// the snapshot is only a cache of the event log, rebuild it when it is gone
+	if !ok {
+		events, err := M.GetEvents(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if len(events) == 0 {
+			return nil, ErrGameNotFound
+		}
+		return domain.Replay(id, events)
+	}
+
+	var g domain.Game
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// admit adds playerID to the set under key unless it is already there or the game is full
+func (M *MemoryGameRepository) admit(key string, playerID string) repository.AdmitResult {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	set := M.members(key, true)
+	if set[playerID] {
+		return repository.AdmitResult{Status: repository.AlreadyPresent, Count: int64(len(set))}
+	}
+	if len(set) >= repository.SeatsPerGame {
+		return repository.AdmitResult{Status: repository.Full, Count: int64(len(set))}
+	}
+	set[playerID] = true
+	return repository.AdmitResult{Status: repository.Admitted, Count: int64(len(set))}
+}
+
+func (M *MemoryGameRepository) AddPlayerToGame(ctx context.Context, gameID string, playerID string) (repository.AdmitResult, error) {
+	return M.admit("Active:game-"+gameID, playerID), nil
+}
+
+func (M *MemoryGameRepository) GetPlayers(ctx context.Context, gameID string) ([]string, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	players := []string{}
+	for p := range M.members("Active:game-"+gameID, false) {
+		players = append(players, p)
+	}
+	return players, nil
+}
+
+func (M *MemoryGameRepository) FindPlayer(ctx context.Context, gameID string, playerID string) bool {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	return M.members("Active:game-"+gameID, false)[playerID]
+}
+
+func (M *MemoryGameRepository) AddPlayerShip(ctx context.Context, gameID string, playerID string) (repository.AdmitResult, error) {
+	return M.admit("Ship:game-"+gameID, playerID), nil
+}
+
+func (M *MemoryGameRepository) SetPresence(ctx context.Context, playerID string, serverID string) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	M.set("presence:"+playerID, serverID, 0)
+	return nil
+}
+
+func (M *MemoryGameRepository) RemovePresence(ctx context.Context, playerID string) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	M.del("presence:" + playerID)
+	return nil
+}
+
+func (M *MemoryGameRepository) GetPlayerServer(ctx context.Context, playerID string) string {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	serverID, _ := M.values["presence:"+playerID].(string)
+	return serverID
+}
+
+func (M *MemoryGameRepository) SetTimeOut(ctx context.Context, key string, limit time.Duration) {
+	grace := 2 * time.Second
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	M.set(key, "active", limit+grace)
+}
+
+func (M *MemoryGameRepository) ClearTimeOut(ctx context.Context, key string) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	M.del(key)
+}
+
+// SaveRoom stores the room record and (re)starts its expiry, public rooms are listed in the lobby as well
+func (M *MemoryGameRepository) SaveRoom(ctx context.Context, room *domain.Room) error {
+	data, err := json.Marshal(room)
+	if err != nil {
+		return err
+	}
+
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	M.set("room:"+room.ID, data, room.TTL())
+	if room.Visibility == domain.VisibilityPublic {
+		M.publicRooms()[room.ID] = room.CreatedAt
+	}
+	return nil
+}
+
+// GetRoom returns the room record, ExpiresAt follows the remaining time of the key
+func (M *MemoryGameRepository) GetRoom(ctx context.Context, roomID string) (*domain.Room, error) {
+	M.mu.Lock()
+	data, ok := M.values["room:"+roomID].([]byte)
+	deadline, hasDeadline := M.deadlines["room:"+roomID]
+	M.mu.Unlock()
+	if !ok {
+		return nil, domain.ErrRoomNotFound
+	}
+
+	var room domain.Room
+	if err := json.Unmarshal(data, &room); err != nil {
+		return nil, err
+	}
+	if hasDeadline {
+		room.ExpiresAt = deadline.UnixMilli()
+	}
+	return &room, nil
+}
+
+func (M *MemoryGameRepository) SetRoomStatus(ctx context.Context, roomID string, status domain.RoomStatus) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	key := "room:" + roomID
+	data, ok := M.values[key].([]byte)
+	if !ok {
+		return nil
+	}
+
+	var room domain.Room
+	if err := json.Unmarshal(data, &room); err != nil {
+		return err
+	}
+	room.Status = status
+	data, err := json.Marshal(room)
+	if err != nil {
+		return err
+	}
+	// the expiry keeps running
+	M.values[key] = data
+	return nil
+}
+
+// TouchRoom pushes the expiry of the room back, rooms nobody plays in expire after domain.RoomTTL.
+// Rooms that were saved with a longer expiry keep it.
+func (M *MemoryGameRepository) TouchRoom(ctx context.Context, roomID string) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	key := "room:" + roomID
+	if _, ok := M.values[key]; !ok {
+		return
+	}
+	if deadline, ok := M.deadlines[key]; !ok || time.Until(deadline) < domain.RoomTTL {
+		M.expire(key, domain.RoomTTL)
+	}
+}
+
+// DeleteRoom removes the room, its lobby listing, its chat and what is left of its games, the event log stays for replays
+func (M *MemoryGameRepository) DeleteRoom(ctx context.Context, roomID string) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	delete(M.publicRooms(), roomID)
+	M.del(
+		"room:"+roomID,
+		"series:room-"+roomID,
+		"game:"+roomID,
+		"turn:"+roomID,
+		"place:"+roomID,
+		"Active:game-"+roomID,
+		"Ship:game-"+roomID,
+		"Rematch:game-"+roomID,
+		"chat:room-"+roomID,
+	)
+	return nil
+}
+
+func (M *MemoryGameRepository) RoomExists(ctx context.Context, roomID string) bool {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	_, ok := M.values["room:"+roomID]
+	return ok
+}
+
+// GetRules returns the rule set the room was created with, rooms without one play the default rules
+func (M *MemoryGameRepository) GetRules(ctx context.Context, roomID string) (domain.RuleSet, error) {
+	room, err := M.GetRoom(ctx, roomID)
+	if errors.Is(err, domain.ErrRoomNotFound) {
+		return domain.DefaultRuleSet(), nil
+	}
+	if err != nil {
+		return domain.RuleSet{}, err
+	}
+	return room.Rules, nil
+}
+
+// GetRoomBot returns the bot difficulty of the room, ok is false for rooms between two players
+func (M *MemoryGameRepository) GetRoomBot(ctx context.Context, roomID string) (string, bool) {
+	room, err := M.GetRoom(ctx, roomID)
+	if err != nil || room.Bot == "" {
+		return "", false
+	}
+	return room.Bot, true
+}
+
+// AppendEvent adds a state transition to the event log of the game, ids follow the Redis stream format
+func (M *MemoryGameRepository) AppendEvent(ctx context.Context, gameID string, e domain.Event) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	M.appendEvent(gameID, e)
+	return nil
+}
+
+// appendEvent adds e to the event log of the game. M.mu must be held.
+func (M *MemoryGameRepository) appendEvent(gameID string, e domain.Event) {
+	M.seq++
+	e.ID = fmt.Sprintf("%d-%d", e.At, M.seq)
+
+	key := "events:game-" + gameID
+	events, _ := M.values[key].([]domain.Event)
+	M.set(key, append(events, e), 24*time.Hour)
+}
+
+// GetEvents returns the event log of the game in order
+func (M *MemoryGameRepository) GetEvents(ctx context.Context, gameID string) ([]domain.Event, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	events, _ := M.values["events:game-"+gameID].([]domain.Event)
+	return append([]domain.Event{}, events...), nil
+}
+
+// AddRematchRequest marks that playerID wants a rematch and returns how many players asked for one
+func (M *MemoryGameRepository) AddRematchRequest(ctx context.Context, gameID string, playerID string) (int64, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	key := "Rematch:game-" + gameID
+	requests := M.members(key, true)
+	requests[playerID] = true
+	M.expire(key, 5*time.Minute)
+	return int64(len(requests)), nil
+}
+
+func (M *MemoryGameRepository) HasRequestedRematch(ctx context.Context, gameID string, playerID string) bool {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	return M.members("Rematch:game-"+gameID, false)[playerID]
+}
+
+// ResetRound clears the placement and rematch bookkeeping so a new round can start in the room
+func (M *MemoryGameRepository) ResetRound(ctx context.Context, gameID string) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	M.del("Ship:game-"+gameID, "Rematch:game-"+gameID)
+	return nil
+}
+
+func (M *MemoryGameRepository) SaveSeries(ctx context.Context, roomID string, s *domain.Series) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	M.set("series:room-"+roomID, data, 0)
+	return nil
+}
+
+// GetSeries returns the series of the room, rooms that play single games have none
+func (M *MemoryGameRepository) GetSeries(ctx context.Context, roomID string) (*domain.Series, error) {
+	M.mu.Lock()
+	data, ok := M.values["series:room-"+roomID].([]byte)
+	M.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	var s domain.Series
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
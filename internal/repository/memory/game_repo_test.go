@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/repotest"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.RunAll(t, func(t *testing.T) repotest.Repository {
+		return NewMemoryGameRepository()
+	})
+}
+
+// a game with long limits must outlive its placement timer
+func TestGameTTL(t *testing.T) {
+	repo := NewMemoryGameRepository()
+	rules := domain.DefaultRuleSet()
+	rules.TurnLimit, rules.PlacementLimit = 180, 300
+	if err := repo.SaveGame(context.Background(), domain.NewGame("A", "B", "slow", rules)); err != nil {
+		t.Fatal(err)
+	}
+
+	repo.mu.Lock()
+	remaining := time.Until(repo.deadlines["game:slow"])
+	repo.mu.Unlock()
+	if remaining <= rules.PlacementDuration() || remaining > rules.GameTTL() {
+		t.Errorf("game ttl: expected about %v, got %v", rules.GameTTL(), remaining)
+	}
+}
+
+// the chat of a tournament room must last as long as the room
+func TestChatTTL(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryGameRepository()
+	msg := domain.NewChatMessage("A", "gg")
+	if err := repo.SaveChat(ctx, "cup", &msg, domain.TournamentTTL); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetChatMute(ctx, "cup", "A", "B", true, domain.TournamentTTL); err != nil {
+		t.Fatal(err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, key := range []string{"chat:room-cup", "chat:mute:cup:A"} {
+		if remaining := time.Until(repo.deadlines[key]); remaining <= domain.RoomTTL || remaining > domain.TournamentTTL {
+			t.Errorf("%s ttl: expected about %v, got %v", key, domain.TournamentTTL, remaining)
+		}
+	}
+}
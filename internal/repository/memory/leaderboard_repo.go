@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// scores returns the sorted set or hash under key. M.mu must be held.
+func (M *MemoryGameRepository) scores(key string) map[string]int {
+	s, ok := M.values[key].(map[string]int)
+	if !ok {
+		s = make(map[string]int)
+		M.values[key] = s
+	}
+	return s
+}
+
+func boardKey(scope string, board domain.Board) string {
+	return fmt.Sprintf("leaderboard:%s:%s", scope, board)
+}
+
+// ranked returns a board best first, ties in reverse player order like ZREVRANGE. M.mu must be held.
+func (M *MemoryGameRepository) ranked(scope string, board domain.Board) []domain.LeaderboardEntry {
+	scores := M.scores(boardKey(scope, board))
+	entries := make([]domain.LeaderboardEntry, 0, len(scores))
+	for p, s := range scores {
+		entries = append(entries, domain.LeaderboardEntry{PlayerID: p, Score: s})
+	}
+	slices.SortFunc(entries, func(a, b domain.LeaderboardEntry) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.PlayerID, a.PlayerID)
+	})
+	for i := range entries {
+		entries[i].Rank = int64(i) + 1
+	}
+	return entries
+}
+
+// RecordResult puts the rating of a ranked game on the boards of scope, counts the win
+// and moves the streak atomically
+func (M *MemoryGameRepository) RecordResult(ctx context.Context, scope string, playerID string, rating int, won bool) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	M.scores(boardKey(scope, domain.BoardRating))[playerID] = rating
+	wins := M.scores(boardKey(scope, domain.BoardWins))
+	best := M.scores(boardKey(scope, domain.BoardStreak))
+	streaks := M.scores(fmt.Sprintf("leaderboard:%s:streaks", scope))
+	if won {
+		wins[playerID]++
+		streaks[playerID]++
+		best[playerID] = max(best[playerID], streaks[playerID])
+		return nil
+	}
+	// a lost game still puts the player on the boards
+	for _, board := range []map[string]int{wins, best} {
+		if _, ok := board[playerID]; !ok {
+			board[playerID] = 0
+		}
+	}
+	streaks[playerID] = 0
+	return nil
+}
+
+// GetLeaderboard returns a page of a board, best first, and how many players are on it
+func (M *MemoryGameRepository) GetLeaderboard(ctx context.Context, scope string, board domain.Board, offset int64, limit int64) ([]domain.LeaderboardEntry, int64, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	entries := M.ranked(scope, board)
+	total := int64(len(entries))
+	if offset >= total {
+		return []domain.LeaderboardEntry{}, total, nil
+	}
+	return entries[offset:min(offset+limit, total)], total, nil
+}
+
+// GetLeaderboardRank returns domain.ErrNotRanked for players who are not on the board
+func (M *MemoryGameRepository) GetLeaderboardRank(ctx context.Context, scope string, board domain.Board, playerID string) (domain.LeaderboardEntry, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	for _, e := range M.ranked(scope, board) {
+		if e.PlayerID == playerID {
+			return e, nil
+		}
+	}
+	return domain.LeaderboardEntry{}, domain.ErrNotRanked
+}
+
+// ClaimSeasonStart is true for exactly one caller per season
+func (M *MemoryGameRepository) ClaimSeasonStart(ctx context.Context, seasonID string) (bool, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	key := "season:started:" + seasonID
+	if _, ok := M.values[key]; ok {
+		return false, nil
+	}
+	M.values[key] = true
+	return true, nil
+}
+
+// ReleaseSeasonStart gives up the claim on a season so its start is tried again
+func (M *MemoryGameRepository) ReleaseSeasonStart(ctx context.Context, seasonID string) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	M.del("season:started:" + seasonID)
+	return nil
+}
+
+// SoftResetRatingBoard soft resets the rating board of scope once for the season
+func (M *MemoryGameRepository) SoftResetRatingBoard(ctx context.Context, scope string, seasonID string) error {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	marker := fmt.Sprintf("season:reset:%s:%s", seasonID, scope)
+	if _, ok := M.values[marker]; ok {
+		return nil
+	}
+	M.values[marker] = true
+
+	board := M.scores(boardKey(scope, domain.BoardRating))
+	for p, points := range board {
+		board[p] = domain.Rating{Points: points}.SoftReset().Points
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// publicRooms returns the lobby index, room id to creation time. M.mu must be held.
+func (M *MemoryGameRepository) publicRooms() map[string]int64 {
+	rooms, ok := M.values["rooms:public"].(map[string]int64)
+	if !ok {
+		rooms = make(map[string]int64)
+		M.values["rooms:public"] = rooms
+	}
+	return rooms
+}
+
+// PublicRooms returns a page of the public rooms, newest first, and how many are listed
+func (M *MemoryGameRepository) PublicRooms(ctx context.Context, offset int64, limit int64) ([]string, int64, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	rooms := M.publicRooms()
+	ids := make([]string, 0, len(rooms))
+	for id := range rooms {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		if c := cmp.Compare(rooms[b], rooms[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(b, a)
+	})
+
+	total := int64(len(ids))
+	if offset >= total {
+		return []string{}, total, nil
+	}
+	return ids[offset:min(offset+limit, total)], total, nil
+}
+
+// RemovePublicRoom takes the room off the lobby, ok is false when it was not listed
+func (M *MemoryGameRepository) RemovePublicRoom(ctx context.Context, roomID string) (bool, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	rooms := M.publicRooms()
+	_, ok := rooms[roomID]
+	delete(rooms, roomID)
+	return ok, nil
+}
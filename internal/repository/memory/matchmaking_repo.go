@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// queue returns the matchmaking queue. M.mu must be held.
+func (M *MemoryGameRepository) queue() map[string]domain.QueueEntry {
+	q, ok := M.values["matchmaking:queue"].(map[string]domain.QueueEntry)
+	if !ok {
+		q = make(map[string]domain.QueueEntry)
+		M.values["matchmaking:queue"] = q
+	}
+	return q
+}
+
+// Enqueue adds the player to the matchmaking queue, a player already waiting keeps
+// the place it has and ok is false
+func (M *MemoryGameRepository) Enqueue(ctx context.Context, entry domain.QueueEntry) (bool, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	q := M.queue()
+	if _, ok := q[entry.PlayerID]; ok {
+		return false, nil
+	}
+	q[entry.PlayerID] = entry
+	return true, nil
+}
+
+// Dequeue takes the player out of the matchmaking queue, ok is false when it was not waiting
+func (M *MemoryGameRepository) Dequeue(ctx context.Context, playerID string) (bool, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	q := M.queue()
+	_, ok := q[playerID]
+	delete(q, playerID)
+	return ok, nil
+}
+
+// QueueEntries returns every player waiting in the matchmaking queue, in no particular order
+func (M *MemoryGameRepository) QueueEntries(ctx context.Context) ([]domain.QueueEntry, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	entries := make([]domain.QueueEntry, 0, len(M.queue()))
+	for _, e := range M.queue() {
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// ClaimMatch takes all players of a match out of the queue at once, ok is false and
+// the queue is left as it is when one of them is not waiting anymore
+func (M *MemoryGameRepository) ClaimMatch(ctx context.Context, playerIDs ...string) (bool, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	q := M.queue()
+	for _, p := range playerIDs {
+		if _, ok := q[p]; !ok {
+			return false, nil
+		}
+	}
+	for _, p := range playerIDs {
+		delete(q, p)
+	}
+	return true, nil
+}
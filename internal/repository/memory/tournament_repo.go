@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// SaveTournament stores t only when the stored tournament still has t.Version, a missing
+// tournament accepts any version. domain.ErrTournamentConflict means another request saved first.
+func (M *MemoryGameRepository) SaveTournament(ctx context.Context, t *domain.Tournament) error {
+	key := "tournament:" + t.ID
+	expected := t.Version
+
+	next := *t
+	next.Version = expected + 1
+	data, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+
+	M.mu.Lock()
+	defer M.mu.Unlock()
+
+	if current, ok := M.values[key].([]byte); ok {
+		var stored struct {
+			Version int64 `json:"version"`
+		}
+		if err := json.Unmarshal(current, &stored); err != nil {
+			return err
+		}
+		if stored.Version != expected {
+			return domain.ErrTournamentConflict
+		}
+	}
+
+	M.set(key, data, domain.TournamentTTL)
+	t.Version = next.Version
+	return nil
+}
+
+func (M *MemoryGameRepository) GetTournament(ctx context.Context, id string) (*domain.Tournament, error) {
+	M.mu.Lock()
+	data, ok := M.values["tournament:"+id].([]byte)
+	M.mu.Unlock()
+	if !ok {
+		return nil, domain.ErrTournamentNotFound
+	}
+
+	var t domain.Tournament
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -1,3 +1,128 @@
 package mongodb
 
-// no need to add auth since this is simple game with no leaderboard
\ No newline at end of file
+import (
+	"context"
+	"errors"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// userDocument is how a user is stored in the users collection
+type userDocument struct {
+	ID           string        `bson:"_id"`
+	Username     string        `bson:"username"`
+	DisplayName  string        `bson:"displayName"`
+	AvatarURL    string        `bson:"avatarURL,omitempty"`
+	PasswordHash []byte        `bson:"passwordHash"`
+	CreatedAt    int64         `bson:"createdAt"`
+	Rating       domain.Rating `bson:"rating"`
+}
+
+// MongoUserRepository keeps registered users in the users collection of db
+type MongoUserRepository struct {
+	users *mongo.Collection
+}
+
+// NewMongoUserRepository makes sure usernames are unique in the users collection
+func NewMongoUserRepository(ctx context.Context, db *mongo.Database) (*MongoUserRepository, error) {
+	users := db.Collection("users")
+	_, err := users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "username", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoUserRepository{users: users}, nil
+}
+
+func (R *MongoUserRepository) CreateUser(ctx context.Context, u *domain.User) error {
+	_, err := R.users.InsertOne(ctx, userDocument(*u))
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrUsernameTaken
+	}
+	return err
+}
+
+func (R *MongoUserRepository) GetUser(ctx context.Context, id string) (*domain.User, error) {
+	return R.findOne(ctx, bson.D{{Key: "_id", Value: id}})
+}
+
+func (R *MongoUserRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return R.findOne(ctx, bson.D{{Key: "username", Value: username}})
+}
+
+func (R *MongoUserRepository) findOne(ctx context.Context, filter bson.D) (*domain.User, error) {
+	var doc userDocument
+	err := R.users.FindOne(ctx, filter).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	u := domain.User(doc)
+	return &u, nil
+}
+
+func (R *MongoUserRepository) UpdateProfile(ctx context.Context, id string, displayName string, avatarURL string) error {
+	result, err := R.users.UpdateByID(ctx, id, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "displayName", Value: displayName},
+		{Key: "avatarURL", Value: avatarURL},
+	}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// AddRatingResult counts a ranked game of the user with one atomic update,
+// so results of games ending at the same time are never lost
+func (R *MongoUserRepository) AddRatingResult(ctx context.Context, userID string, delta int, won bool) (domain.Rating, error) {
+	result := "rating.losses"
+	if won {
+		result = "rating.wins"
+	}
+
+	var doc userDocument
+	err := R.users.FindOneAndUpdate(ctx,
+		bson.D{{Key: "_id", Value: userID}},
+		bson.D{{Key: "$inc", Value: bson.D{
+			{Key: "rating.points", Value: delta},
+			{Key: "rating.games", Value: 1},
+			{Key: result, Value: 1},
+		}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Rating{}, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return domain.Rating{}, err
+	}
+	return doc.Rating, nil
+}
+
+// SoftResetRatings pulls every rating towards the default with one update on the server,
+// the same halving as domain.Rating.SoftReset
+func (R *MongoUserRepository) SoftResetRatings(ctx context.Context) error {
+	points := bson.D{{Key: "$toInt", Value: bson.D{{Key: "$add", Value: bson.A{
+		domain.DefaultRating,
+		bson.D{{Key: "$trunc", Value: bson.D{{Key: "$divide", Value: bson.A{
+			bson.D{{Key: "$subtract", Value: bson.A{"$rating.points", domain.DefaultRating}}},
+			2,
+		}}}}},
+	}}}}}
+
+	_, err := R.users.UpdateMany(ctx,
+		bson.D{},
+		mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "rating.points", Value: points}}}}},
+	)
+	return err
+}
@@ -1,11 +1,130 @@
 package redis
 
-import "github.com/redis/go-redis/v9"
+import (
+	"context"
+	"encoding/json"
+	"time"
 
-type ChatRedisRepository struct {
-	redisClient *redis.Client
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// SaveChat appends msg to the chat stream of the room and fills in its ID,
+// the stream keeps the last domain.ChatHistoryLimit messages and lives for ttl like the room.
+func (R *RedisGameRepository) SaveChat(ctx context.Context, roomID string, msg *domain.ChatMessage, ttl time.Duration) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	key := "chat:room-" + roomID
+	pipe := R.RedisClient.TxPipeline()
+	id := pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: domain.ChatHistoryLimit,
+		Values: map[string]interface{}{"chat": data},
+	})
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	msg.ID = id.Val()
+	return nil
+}
+
+// GetChat returns up to limit messages of the room sent before the message with ID before,
+// oldest first. An empty before returns the latest messages.
+func (R *RedisGameRepository) GetChat(ctx context.Context, roomID string, before string, limit int64) ([]domain.ChatMessage, error) {
+	end := "+"
+	if before != "" {
+		end = "(" + before
+	}
+	entries, err := R.RedisClient.XRevRangeN(ctx, "chat:room-"+roomID, end, "-", limit).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]domain.ChatMessage, len(entries))
+	for i, entry := range entries {
+		raw, _ := entry.Values["chat"].(string)
+
+		var msg domain.ChatMessage
+		if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+			return nil, err
+		}
+		msg.ID = entry.ID
+		// XREVRANGE reads the newest message first
+		messages[len(entries)-1-i] = msg
+	}
+	return messages, nil
 }
 
-func (c *ChatRedisRepository) SaveChat()  {
-	
-}
\ No newline at end of file
+// GetChatMessage returns the message with ID id from the chat of the room
+func (R *RedisGameRepository) GetChatMessage(ctx context.Context, roomID string, id string) (*domain.ChatMessage, error) {
+	entries, err := R.RedisClient.XRange(ctx, "chat:room-"+roomID, id, id).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, domain.ErrChatNotFound
+	}
+
+	raw, _ := entries[0].Values["chat"].(string)
+	var msg domain.ChatMessage
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		return nil, err
+	}
+	msg.ID = entries[0].ID
+	return &msg, nil
+}
+
+// CountChat counts a message of playerID and returns how many the player sent in the current window
+func (R *RedisGameRepository) CountChat(ctx context.Context, roomID string, playerID string) (int64, error) {
+	key := "chat:rate:" + roomID + ":" + playerID
+
+	pipe := R.RedisClient.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	// the window starts with the first message, later ones must not push it back
+	pipe.ExpireNX(ctx, key, domain.ChatRateWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
+
+// SetChatMute mutes or unmutes target for playerID in the room, the mute lives for ttl like the room
+func (R *RedisGameRepository) SetChatMute(ctx context.Context, roomID string, playerID string, target string, muted bool, ttl time.Duration) error {
+	key := "chat:mute:" + roomID + ":" + playerID
+	if !muted {
+		return R.RedisClient.SRem(ctx, key, target).Err()
+	}
+
+	pipe := R.RedisClient.TxPipeline()
+	pipe.SAdd(ctx, key, target)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetChatMutes returns the players playerID muted in the room
+func (R *RedisGameRepository) GetChatMutes(ctx context.Context, roomID string, playerID string) ([]string, error) {
+	return R.RedisClient.SMembers(ctx, "chat:mute:"+roomID+":"+playerID).Result()
+}
+
+// SaveChatReport files report for moderators and fills in its ID, reports do not expire
+func (R *RedisGameRepository) SaveChatReport(ctx context.Context, report *domain.ChatReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	id, err := R.RedisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: "chat:reports",
+		Values: map[string]interface{}{"report": data},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	report.ID = id
+	return nil
+}
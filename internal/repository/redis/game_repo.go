@@ -7,32 +7,74 @@ import (
 	"log"
 	"time"
 
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository"
 	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
 	"github.com/redis/go-redis/v9"
 )
 
-var (
-	ErrGameFull = errors.New("Game Full")
-	ErrPlayerAlreadyPlaced = errors.New("Ship has already placed in the game")
-)
-
 type RedisGameRepository struct {
 	RedisClient *redis.Client
 }
 
-func (R *RedisGameRepository) SaveGame(ctx context.Context,g *domain.Game)	error {
-	data, err := json.Marshal(g)
+// SaveGame stores g only when the stored snapshot still has g.Version and appends events
+// in the same transaction, so the event log follows the order of the saves. A missing snapshot
+// accepts any version. domain.ErrVersionConflict means another request saved the game first.
+func (R *RedisGameRepository) SaveGame(ctx context.Context,g *domain.Game,events ...domain.Event)	error {
+	key := "game:"+g.ID
+	expected := g.Version
+
+	next := *g
+	next.Version = expected+1
+	data, err := json.Marshal(next)
 
 	if err!=nil {
 		return err
 	}
 
-	return R.RedisClient.Set(ctx,"game:"+g.ID,data,2*time.Minute).Err()
+	err = R.RedisClient.Watch(ctx,func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx,key).Bytes()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if err == nil {
+			var stored struct {
+				Version int64 `json:"version"`
+			}
+			if err := json.Unmarshal(current,&stored); err != nil {
+				return err
+			}
+			if stored.Version != expected {
+				return domain.ErrVersionConflict
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx,func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx,key,data,g.Rules.GameTTL())
+			return addEvents(ctx,pipe,g.ID,events...)
+		})
+		return err
+	},key)
+
+	// the snapshot changed between the read and the write
+	if err == redis.TxFailedErr {
+		return domain.ErrVersionConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	g.Version = next.Version
+	return nil
 }
 
 func (R *RedisGameRepository) GetGame(ctx context.Context,id string) (*domain.Game,error) {
 	data,err := R.RedisClient.Get(ctx,"game:"+id).Bytes()
 
+	// the snapshot is only a cache of the event log, rebuild it when it is gone
+	if err == redis.Nil {
+		return R.RebuildGame(ctx,id)
+	}
+
 	if err!=nil {
 		return nil,err
 	}
@@ -46,30 +88,33 @@ func (R *RedisGameRepository) GetGame(ctx context.Context,id string) (*domain.Ga
 	return &g, nil
 }
 
-func (R *RedisGameRepository) LockGame(ctx context.Context, gameID string) error {
-	lock := "lock:game-"+gameID
-	ok, err := R.RedisClient.SetNX(ctx,lock,"locked",5*time.Second).Result()
-	if err!=nil || !ok {
-		return errors.New("faild to lock game")
-	}
-	return nil
-}
+// admitScript adds ARGV[1] to the set KEYS[1] unless it is already there or holds ARGV[2] members,
+// it runs inside Redis so concurrent joins can not overfill the set.
+var admitScript = redis.NewScript(`
+if redis.call('SISMEMBER', KEYS[1], ARGV[1]) == 1 then
+	return {1, redis.call('SCARD', KEYS[1])}
+end
+local size = redis.call('SCARD', KEYS[1])
+if size >= tonumber(ARGV[2]) then
+	return {2, size}
+end
+redis.call('SADD', KEYS[1], ARGV[1])
+return {0, size + 1}
+`)
 
-func (R *RedisGameRepository) DeleteLock(ctx context.Context, gameID string) error {
-	lock := "lock:game-"+gameID
-	return R.RedisClient.Del(ctx,lock).Err()
+func (R *RedisGameRepository) admit(ctx context.Context, key string, playerID string) (repository.AdmitResult, error) {
+	res, err := admitScript.Run(ctx, R.RedisClient, []string{key}, playerID, repository.SeatsPerGame).Int64Slice()
+	if err != nil {
+		return repository.AdmitResult{}, err
+	}
+	if len(res) != 2 {
+		return repository.AdmitResult{}, errors.New("unexpected admit script result")
+	}
+	return repository.AdmitResult{Status: repository.AdmitStatus(res[0]), Count: res[1]}, nil
 }
 
-func (R *RedisGameRepository) AddPlayerToGame(ctx context.Context, gameId string, playerID string) ( int64 ,error) {
-	activeGameKey := "Active:game-"+gameId
-	numberPlayers := R.RedisClient.SCard(ctx,activeGameKey).Val()
-
-	if numberPlayers >=2 {
-		return 0,ErrGameFull
-	}
-	R.RedisClient.SAdd(ctx,activeGameKey,playerID)
-	numberPlayers = R.RedisClient.SCard(ctx,activeGameKey).Val()
-	return numberPlayers,nil
+func (R *RedisGameRepository) AddPlayerToGame(ctx context.Context, gameId string, playerID string) (repository.AdmitResult, error) {
+	return R.admit(ctx, "Active:game-"+gameId, playerID)
 }
 
 func (R *RedisGameRepository) GetPlayers(ctx context.Context,gameID string) ([]string,error) {
@@ -102,34 +147,260 @@ func (R *RedisGameRepository) GetPlayerServer(ctx context.Context,playerID strin
 	return serverID
 }
 
-func (R *RedisGameRepository) AddPlayerShip(ctx context.Context,gameId string, playerId string) (int64,error) {
-	gameShipKey := "Ship:game-"+gameId
+func (R *RedisGameRepository) AddPlayerShip(ctx context.Context,gameId string, playerId string) (repository.AdmitResult,error) {
+	return R.admit(ctx, "Ship:game-"+gameId, playerId)
+}
 
-	check,err := R.RedisClient.SIsMember(ctx,gameShipKey,playerId).Result()
+func (R *RedisGameRepository) SetTimeOut(ctx context.Context,key string,limit time.Duration)  {
+	grace := 2*time.Second
+	err := R.RedisClient.Set(ctx, key, "active", limit + grace).Err()
 	if err!= nil {
-		return -1,err
+		log.Println("Failed to start timer: ",err)
 	}
+}
+
+func (R *RedisGameRepository) ClearTimeOut(ctx context.Context,key string) {
+	R.RedisClient.Del(ctx,key)
+}
+
+// SetPresence tells the other servers which server holds the connection of the player
+func (R *RedisGameRepository) SetPresence(ctx context.Context,playerID string,serverID string) error {
+	return R.RedisClient.HSet(ctx,"presence",playerID,serverID).Err()
+}
+
+func (R *RedisGameRepository) RemovePresence(ctx context.Context,playerID string) error {
+	return R.RedisClient.HDel(ctx,"presence",playerID).Err()
+}
 
-	if check {
-		return -1,ErrPlayerAlreadyPlaced
+// SaveRoom stores the room record and (re)starts its expiry, public rooms are listed in the lobby as well
+func (R *RedisGameRepository) SaveRoom(ctx context.Context, room *domain.Room) error {
+	data, err := json.Marshal(room)
+	if err != nil {
+		return err
 	}
-	
-	size,err := R.RedisClient.SCard(ctx,gameShipKey).Result()
+	_, err = R.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "room:"+room.ID, data, room.TTL())
+		if room.Visibility == domain.VisibilityPublic {
+			pipe.ZAdd(ctx, "rooms:public", redis.Z{Score: float64(room.CreatedAt), Member: room.ID})
+		}
+		return nil
+	})
+	return err
+}
 
-	if size >=2 {
-		return -1, ErrGameFull
+// GetRoom returns the room record, ExpiresAt follows the remaining time of the key
+func (R *RedisGameRepository) GetRoom(ctx context.Context, roomID string) (*domain.Room, error) {
+	key := "room:" + roomID
+	pipe := R.RedisClient.Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
 	}
 
-	R.RedisClient.SAdd(ctx,gameShipKey,playerId)
-	
-	return R.RedisClient.SCard(ctx,gameShipKey).Val(),nil
+	data, err := get.Bytes()
+	if err == redis.Nil {
+		return nil, domain.ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
 
+	var room domain.Room
+	if err := json.Unmarshal(data, &room); err != nil {
+		return nil, err
+	}
+	if remaining := ttl.Val(); remaining > 0 {
+		room.ExpiresAt = time.Now().Add(remaining).UnixMilli()
+	}
+	return &room, nil
 }
 
-func (R *RedisGameRepository) SetTimeOut(ctx context.Context,key string,limit time.Duration)  {
-	grace := 2*time.Second
-	err := R.RedisClient.Set(ctx, key, "active", limit + grace).Err()
-	if err!= nil {
-		log.Println("Failed to start timer: ",err)
+func (R *RedisGameRepository) SetRoomStatus(ctx context.Context, roomID string, status domain.RoomStatus) error {
+	room, err := R.GetRoom(ctx, roomID)
+	if errors.Is(err, domain.ErrRoomNotFound) {
+		return nil
 	}
-}
\ No newline at end of file
+	if err != nil {
+		return err
+	}
+
+	room.Status = status
+	data, err := json.Marshal(room)
+	if err != nil {
+		return err
+	}
+	return R.RedisClient.Set(ctx, "room:"+roomID, data, redis.KeepTTL).Err()
+}
+
+// touchScript pushes the expiry of a key back to ARGV[1] milliseconds, a longer expiry is kept
+var touchScript = redis.NewScript(`
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[1]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// TouchRoom pushes the expiry of the room back, rooms nobody plays in expire after domain.RoomTTL.
+// Rooms that were saved with a longer expiry keep it.
+func (R *RedisGameRepository) TouchRoom(ctx context.Context, roomID string) {
+	touchScript.Run(ctx, R.RedisClient, []string{"room:" + roomID}, domain.RoomTTL.Milliseconds())
+}
+
+// DeleteRoom removes the room, its lobby listing, its chat and what is left of its games, the event log stays for replays
+func (R *RedisGameRepository) DeleteRoom(ctx context.Context, roomID string) error {
+	if err := R.RedisClient.ZRem(ctx, "rooms:public", roomID).Err(); err != nil {
+		return err
+	}
+	return R.RedisClient.Del(ctx,
+		"room:"+roomID,
+		"series:room-"+roomID,
+		"game:"+roomID,
+		"turn:"+roomID,
+		"place:"+roomID,
+		"Active:game-"+roomID,
+		"Ship:game-"+roomID,
+		"Rematch:game-"+roomID,
+		"chat:room-"+roomID,
+	).Err()
+}
+
+func (R *RedisGameRepository) RoomExists(ctx context.Context, roomID string) bool {
+	return R.RedisClient.Exists(ctx, "room:"+roomID).Val() == 1
+}
+
+// GetRules returns the rule set the room was created with, rooms without one play the default rules
+func (R *RedisGameRepository) GetRules(ctx context.Context, roomID string) (domain.RuleSet, error) {
+	room, err := R.GetRoom(ctx, roomID)
+	if errors.Is(err, domain.ErrRoomNotFound) {
+		return domain.DefaultRuleSet(), nil
+	}
+	if err != nil {
+		return domain.RuleSet{}, err
+	}
+	return room.Rules, nil
+}
+
+// GetRoomBot returns the bot difficulty of the room, ok is false for rooms between two players
+func (R *RedisGameRepository) GetRoomBot(ctx context.Context, roomID string) (string, bool) {
+	room, err := R.GetRoom(ctx, roomID)
+	if err != nil || room.Bot == "" {
+		return "", false
+	}
+	return room.Bot, true
+}
+
+// AppendEvent adds a state transition to the event log of the game,
+// the log outlives the snapshot so finished games can still be replayed.
+func (R *RedisGameRepository) AppendEvent(ctx context.Context, gameID string, e domain.Event) error {
+	pipe := R.RedisClient.TxPipeline()
+	if err := addEvents(ctx, pipe, gameID, e); err != nil {
+		return err
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func addEvents(ctx context.Context, pipe redis.Pipeliner, gameID string, events ...domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	key := "events:game-" + gameID
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: key,
+			Values: map[string]interface{}{"event": data},
+		})
+	}
+	pipe.Expire(ctx, key, 24*time.Hour)
+	return nil
+}
+
+// GetEvents returns the event log of the game in order
+func (R *RedisGameRepository) GetEvents(ctx context.Context, gameID string) ([]domain.Event, error) {
+	entries, err := R.RedisClient.XRange(ctx, "events:game-"+gameID, "-", "+").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.Event, 0, len(entries))
+	for _, entry := range entries {
+		raw, ok := entry.Values["event"].(string)
+		if !ok {
+			continue
+		}
+
+		var e domain.Event
+		if err := json.Unmarshal([]byte(raw), &e); err != nil {
+			return nil, err
+		}
+		e.ID = entry.ID
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// RebuildGame derives the game snapshot by replaying its event log
+func (R *RedisGameRepository) RebuildGame(ctx context.Context, gameID string) (*domain.Game, error) {
+	events, err := R.GetEvents(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, redis.Nil
+	}
+	return domain.Replay(gameID, events)
+}
+
+// AddRematchRequest marks that playerID wants a rematch and returns how many players asked for one
+func (R *RedisGameRepository) AddRematchRequest(ctx context.Context, gameID string, playerID string) (int64, error) {
+	key := "Rematch:game-" + gameID
+
+	pipe := R.RedisClient.TxPipeline()
+	pipe.SAdd(ctx, key, playerID)
+	size := pipe.SCard(ctx, key)
+	pipe.Expire(ctx, key, 5*time.Minute)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return size.Val(), nil
+}
+
+func (R *RedisGameRepository) HasRequestedRematch(ctx context.Context, gameID string, playerID string) bool {
+	return R.RedisClient.SIsMember(ctx, "Rematch:game-"+gameID, playerID).Val()
+}
+
+// ResetRound clears the placement and rematch bookkeeping so a new round can start in the room
+func (R *RedisGameRepository) ResetRound(ctx context.Context, gameID string) error {
+	return R.RedisClient.Del(ctx, "Ship:game-"+gameID, "Rematch:game-"+gameID).Err()
+}
+
+func (R *RedisGameRepository) SaveSeries(ctx context.Context, roomID string, s *domain.Series) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return R.RedisClient.Set(ctx, "series:room-"+roomID, data, 0).Err()
+}
+
+// GetSeries returns the series of the room, rooms that play single games have none
+func (R *RedisGameRepository) GetSeries(ctx context.Context, roomID string) (*domain.Series, error) {
+	data, err := R.RedisClient.Get(ctx, "series:room-"+roomID).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s domain.Series
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
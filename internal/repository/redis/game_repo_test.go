@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/repotest"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.RunAll(t, func(t *testing.T) repotest.Repository {
+		server := miniredis.RunT(t)
+		rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { rdb.Close() })
+		return &RedisGameRepository{RedisClient: rdb}
+	})
+}
+
+// a game with long limits must outlive its placement timer
+func TestGameTTL(t *testing.T) {
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer rdb.Close()
+	repo := &RedisGameRepository{RedisClient: rdb}
+
+	rules := domain.DefaultRuleSet()
+	rules.TurnLimit, rules.PlacementLimit = 180, 300
+	if err := repo.SaveGame(context.Background(), domain.NewGame("A", "B", "slow", rules)); err != nil {
+		t.Fatal(err)
+	}
+
+	server.FastForward(rules.PlacementDuration())
+	if _, err := repo.GetGame(context.Background(), "slow"); err != nil {
+		t.Errorf("game after the placement limit: %v", err)
+	}
+	if ttl := server.TTL("game:slow"); ttl != domain.GameGrace {
+		t.Errorf("remaining ttl: expected %v, got %v", domain.GameGrace, ttl)
+	}
+	server.FastForward(domain.GameGrace + time.Second)
+	if _, err := repo.GetGame(context.Background(), "slow"); err == nil {
+		t.Error("expected the game to expire after the grace period")
+	}
+}
+
+// the chat of a tournament room must last as long as the room
+func TestChatTTL(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer rdb.Close()
+	repo := &RedisGameRepository{RedisClient: rdb}
+
+	msg := domain.NewChatMessage("A", "gg")
+	if err := repo.SaveChat(ctx, "cup", &msg, domain.TournamentTTL); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetChatMute(ctx, "cup", "A", "B", true, domain.TournamentTTL); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"chat:room-cup", "chat:mute:cup:A"} {
+		if ttl := server.TTL(key); ttl != domain.TournamentTTL {
+			t.Errorf("%s ttl: expected %v, got %v", key, domain.TournamentTTL, ttl)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// recordScript updates the rating, wins and streak boards of a player in one step,
+// the streak board keeps the longest streak and the streaks hash the running one
+var recordScript = redis.NewScript(`
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+if ARGV[3] == '1' then
+	redis.call('ZINCRBY', KEYS[2], 1, ARGV[1])
+	local streak = redis.call('HINCRBY', KEYS[4], ARGV[1], 1)
+	redis.call('ZADD', KEYS[3], 'GT', streak, ARGV[1])
+else
+	redis.call('ZADD', KEYS[2], 'NX', 0, ARGV[1])
+	redis.call('ZADD', KEYS[3], 'NX', 0, ARGV[1])
+	redis.call('HSET', KEYS[4], ARGV[1], 0)
+end
+return 1
+`)
+
+// softResetScript pulls every score of the rating board KEYS[1] halfway back to ARGV[1]
+// like domain.Rating.SoftReset. KEYS[2] marks the board as reset for the season, so a
+// retried season start does not halve the scores twice.
+var softResetScript = redis.NewScript(`
+if not redis.call('SET', KEYS[2], 1, 'NX') then
+	return 0
+end
+local default = tonumber(ARGV[1])
+local scores = redis.call('ZRANGE', KEYS[1], 0, -1, 'WITHSCORES')
+for i = 1, #scores, 2 do
+	local d = (tonumber(scores[i+1]) - default) / 2
+	if d >= 0 then d = math.floor(d) else d = math.ceil(d) end
+	redis.call('ZADD', KEYS[1], default + d, scores[i])
+end
+return 1
+`)
+
+func boardKey(scope string, board domain.Board) string {
+	return fmt.Sprintf("leaderboard:%s:%s", scope, board)
+}
+
+// RecordResult puts the rating of a ranked game on the boards of scope, counts the win
+// and moves the streak atomically
+func (R *RedisGameRepository) RecordResult(ctx context.Context, scope string, playerID string, rating int, won bool) error {
+	keys := []string{
+		boardKey(scope, domain.BoardRating),
+		boardKey(scope, domain.BoardWins),
+		boardKey(scope, domain.BoardStreak),
+		fmt.Sprintf("leaderboard:%s:streaks", scope),
+	}
+	w := 0
+	if won {
+		w = 1
+	}
+	return recordScript.Run(ctx, R.RedisClient, keys, playerID, rating, w).Err()
+}
+
+// GetLeaderboard returns a page of a board, best first, and how many players are on it
+func (R *RedisGameRepository) GetLeaderboard(ctx context.Context, scope string, board domain.Board, offset int64, limit int64) ([]domain.LeaderboardEntry, int64, error) {
+	key := boardKey(scope, board)
+	total, err := R.RedisClient.ZCard(ctx, key).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scores, err := R.RedisClient.ZRevRangeWithScores(ctx, key, offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]domain.LeaderboardEntry, 0, len(scores))
+	for i, z := range scores {
+		entries = append(entries, domain.LeaderboardEntry{
+			Rank:     offset + int64(i) + 1,
+			PlayerID: z.Member.(string),
+			Score:    int(z.Score),
+		})
+	}
+	return entries, total, nil
+}
+
+// GetLeaderboardRank returns domain.ErrNotRanked for players who are not on the board
+func (R *RedisGameRepository) GetLeaderboardRank(ctx context.Context, scope string, board domain.Board, playerID string) (domain.LeaderboardEntry, error) {
+	key := boardKey(scope, board)
+	rank, err := R.RedisClient.ZRevRank(ctx, key, playerID).Result()
+	if errors.Is(err, redis.Nil) {
+		return domain.LeaderboardEntry{}, domain.ErrNotRanked
+	}
+	if err != nil {
+		return domain.LeaderboardEntry{}, err
+	}
+
+	score, err := R.RedisClient.ZScore(ctx, key, playerID).Result()
+	if err != nil {
+		return domain.LeaderboardEntry{}, err
+	}
+	return domain.LeaderboardEntry{
+		Rank:     rank + 1,
+		PlayerID: playerID,
+		Score:    int(score),
+	}, nil
+}
+
+// ClaimSeasonStart is true for exactly one caller per season
+func (R *RedisGameRepository) ClaimSeasonStart(ctx context.Context, seasonID string) (bool, error) {
+	return R.RedisClient.SetNX(ctx, "season:started:"+seasonID, 1, 0).Result()
+}
+
+// ReleaseSeasonStart gives up the claim on a season so its start is tried again
+func (R *RedisGameRepository) ReleaseSeasonStart(ctx context.Context, seasonID string) error {
+	return R.RedisClient.Del(ctx, "season:started:"+seasonID).Err()
+}
+
+// SoftResetRatingBoard soft resets the rating board of scope once for the season
+func (R *RedisGameRepository) SoftResetRatingBoard(ctx context.Context, scope string, seasonID string) error {
+	keys := []string{boardKey(scope, domain.BoardRating), fmt.Sprintf("season:reset:%s:%s", seasonID, scope)}
+	return softResetScript.Run(ctx, R.RedisClient, keys, domain.DefaultRating).Err()
+}
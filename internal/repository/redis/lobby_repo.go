@@ -0,0 +1,24 @@
+package redis
+
+import (
+	"context"
+)
+
+// PublicRooms returns a page of the public rooms, newest first, and how many are listed
+func (R *RedisGameRepository) PublicRooms(ctx context.Context, offset int64, limit int64) ([]string, int64, error) {
+	total, err := R.RedisClient.ZCard(ctx, "rooms:public").Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	ids, err := R.RedisClient.ZRevRange(ctx, "rooms:public", offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	return ids, total, nil
+}
+
+// RemovePublicRoom takes the room off the lobby, ok is false when it was not listed
+func (R *RedisGameRepository) RemovePublicRoom(ctx context.Context, roomID string) (bool, error) {
+	n, err := R.RedisClient.ZRem(ctx, "rooms:public", roomID).Result()
+	return n == 1, err
+}
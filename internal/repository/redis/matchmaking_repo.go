@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// claimScript removes every player of a match from the queue, or none of them
+// when one already left or was matched by another server
+var claimScript = redis.NewScript(`
+for _, player in ipairs(ARGV) do
+	if redis.call('HEXISTS', KEYS[1], player) == 0 then
+		return 0
+	end
+end
+redis.call('HDEL', KEYS[1], unpack(ARGV))
+return 1
+`)
+
+// Enqueue adds the player to the matchmaking queue, a player already waiting keeps
+// the place it has and ok is false
+func (R *RedisGameRepository) Enqueue(ctx context.Context, entry domain.QueueEntry) (bool, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return false, err
+	}
+	return R.RedisClient.HSetNX(ctx, "matchmaking:queue", entry.PlayerID, data).Result()
+}
+
+// Dequeue takes the player out of the matchmaking queue, ok is false when it was not waiting
+func (R *RedisGameRepository) Dequeue(ctx context.Context, playerID string) (bool, error) {
+	n, err := R.RedisClient.HDel(ctx, "matchmaking:queue", playerID).Result()
+	return n == 1, err
+}
+
+// QueueEntries returns every player waiting in the matchmaking queue, in no particular order
+func (R *RedisGameRepository) QueueEntries(ctx context.Context) ([]domain.QueueEntry, error) {
+	values, err := R.RedisClient.HGetAll(ctx, "matchmaking:queue").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]domain.QueueEntry, 0, len(values))
+	for _, raw := range values {
+		var e domain.QueueEntry
+		if err := json.Unmarshal([]byte(raw), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// ClaimMatch takes all players of a match out of the queue at once, ok is false and
+// the queue is left as it is when one of them is not waiting anymore
+func (R *RedisGameRepository) ClaimMatch(ctx context.Context, playerIDs ...string) (bool, error) {
+	args := make([]interface{}, len(playerIDs))
+	for i, p := range playerIDs {
+		args[i] = p
+	}
+	claimed, err := claimScript.Run(ctx, R.RedisClient, []string{"matchmaking:queue"}, args...).Int()
+	return claimed == 1, err
+}
@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// SaveTournament stores t only when the stored tournament still has t.Version, a missing
+// tournament accepts any version. domain.ErrTournamentConflict means another request saved first.
+func (R *RedisGameRepository) SaveTournament(ctx context.Context, t *domain.Tournament) error {
+	key := "tournament:" + t.ID
+	expected := t.Version
+
+	next := *t
+	next.Version = expected + 1
+	data, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+
+	err = R.RedisClient.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, key).Bytes()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if err == nil {
+			var stored struct {
+				Version int64 `json:"version"`
+			}
+			if err := json.Unmarshal(current, &stored); err != nil {
+				return err
+			}
+			if stored.Version != expected {
+				return domain.ErrTournamentConflict
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, domain.TournamentTTL)
+			return nil
+		})
+		return err
+	}, key)
+
+	// the tournament changed between the read and the write
+	if err == redis.TxFailedErr {
+		return domain.ErrTournamentConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	t.Version = next.Version
+	return nil
+}
+
+func (R *RedisGameRepository) GetTournament(ctx context.Context, id string) (*domain.Tournament, error) {
+	data, err := R.RedisClient.Get(ctx, "tournament:"+id).Bytes()
+	if err == redis.Nil {
+		return nil, domain.ErrTournamentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t domain.Tournament
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
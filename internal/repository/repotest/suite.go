@@ -0,0 +1,632 @@
+// Package repotest holds tests every game repository has to pass, each repository
+// runs them against its own storage.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// GameRepository is the part of a game repository RunConcurrency exercises
+type GameRepository interface {
+	AddPlayerToGame(ctx context.Context, gameID string, playerID string) (repository.AdmitResult, error)
+	AddPlayerShip(ctx context.Context, gameID string, playerID string) (repository.AdmitResult, error)
+	GetPlayers(ctx context.Context, gameID string) ([]string, error)
+	SaveGame(ctx context.Context, g *domain.Game, events ...domain.Event) error
+	GetGame(ctx context.Context, id string) (*domain.Game, error)
+}
+
+// RoomRepository is exercised by RunRooms, the other suites use it to set up and drop rooms
+type RoomRepository interface {
+	SaveRoom(ctx context.Context, room *domain.Room) error
+	GetRoom(ctx context.Context, roomID string) (*domain.Room, error)
+	SetRoomStatus(ctx context.Context, roomID string, status domain.RoomStatus) error
+	TouchRoom(ctx context.Context, roomID string)
+	DeleteRoom(ctx context.Context, roomID string) error
+	RoomExists(ctx context.Context, roomID string) bool
+	GetRules(ctx context.Context, roomID string) (domain.RuleSet, error)
+}
+
+// ChatRepository is exercised by RunChat
+type ChatRepository interface {
+	SaveChat(ctx context.Context, roomID string, msg *domain.ChatMessage, ttl time.Duration) error
+	GetChat(ctx context.Context, roomID string, before string, limit int64) ([]domain.ChatMessage, error)
+	GetChatMessage(ctx context.Context, roomID string, id string) (*domain.ChatMessage, error)
+	CountChat(ctx context.Context, roomID string, playerID string) (int64, error)
+	SetChatMute(ctx context.Context, roomID string, playerID string, target string, muted bool, ttl time.Duration) error
+	GetChatMutes(ctx context.Context, roomID string, playerID string) ([]string, error)
+}
+
+// QueueRepository is exercised by RunQueue
+type QueueRepository interface {
+	Enqueue(ctx context.Context, entry domain.QueueEntry) (bool, error)
+	Dequeue(ctx context.Context, playerID string) (bool, error)
+	QueueEntries(ctx context.Context) ([]domain.QueueEntry, error)
+	ClaimMatch(ctx context.Context, playerIDs ...string) (bool, error)
+}
+
+// LeaderboardRepository is exercised by RunLeaderboard
+type LeaderboardRepository interface {
+	RecordResult(ctx context.Context, scope string, playerID string, rating int, won bool) error
+	GetLeaderboard(ctx context.Context, scope string, board domain.Board, offset int64, limit int64) ([]domain.LeaderboardEntry, int64, error)
+	GetLeaderboardRank(ctx context.Context, scope string, board domain.Board, playerID string) (domain.LeaderboardEntry, error)
+	ClaimSeasonStart(ctx context.Context, seasonID string) (bool, error)
+	ReleaseSeasonStart(ctx context.Context, seasonID string) error
+	SoftResetRatingBoard(ctx context.Context, scope string, seasonID string) error
+}
+
+// TournamentRepository is exercised by RunTournaments
+type TournamentRepository interface {
+	SaveTournament(ctx context.Context, t *domain.Tournament) error
+	GetTournament(ctx context.Context, id string) (*domain.Tournament, error)
+}
+
+// LobbyRepository is exercised by RunLobby
+type LobbyRepository interface {
+	PublicRooms(ctx context.Context, offset int64, limit int64) ([]string, int64, error)
+	RemovePublicRoom(ctx context.Context, roomID string) (bool, error)
+}
+
+// Repository is a whole game repository, RunAll hands it to every suite
+type Repository interface {
+	GameRepository
+	RoomRepository
+	ChatRepository
+	QueueRepository
+	LeaderboardRepository
+	TournamentRepository
+	LobbyRepository
+}
+
+const goroutines = 64
+
+// hammer runs op from many goroutines at once and counts the outcomes
+func hammer(t *testing.T, op func(i int) (repository.AdmitResult, error)) map[repository.AdmitStatus]int {
+	t.Helper()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	counts := make(map[repository.AdmitStatus]int)
+	start := make(chan struct{})
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			res, err := op(i)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res.Count > repository.SeatsPerGame {
+				t.Errorf("count: expected at most %d, got %d", repository.SeatsPerGame, res.Count)
+			}
+			mu.Lock()
+			counts[res.Status]++
+			mu.Unlock()
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return counts
+}
+
+func expectCounts(t *testing.T, got map[repository.AdmitStatus]int, want map[repository.AdmitStatus]int) {
+	t.Helper()
+	for status, n := range want {
+		if got[status] != n {
+			t.Errorf("%s: expected %d, got %d", status, n, got[status])
+		}
+	}
+}
+
+// RunAll runs every suite as a subtest, each against a fresh repository from newRepo
+func RunAll(t *testing.T, newRepo func(t *testing.T) Repository) {
+	suites := []struct {
+		name string
+		run  func(t *testing.T, repo Repository)
+	}{
+		{"Concurrency", func(t *testing.T, repo Repository) { RunConcurrency(t, repo) }},
+		{"Rooms", func(t *testing.T, repo Repository) { RunRooms(t, repo) }},
+		{"Chat", func(t *testing.T, repo Repository) { RunChat(t, repo, repo) }},
+		{"Queue", func(t *testing.T, repo Repository) { RunQueue(t, repo) }},
+		{"Leaderboard", func(t *testing.T, repo Repository) { RunLeaderboard(t, repo) }},
+		{"Tournaments", func(t *testing.T, repo Repository) { RunTournaments(t, repo, repo) }},
+		{"Lobby", func(t *testing.T, repo Repository) { RunLobby(t, repo, repo) }},
+	}
+	for _, s := range suites {
+		t.Run(s.name, func(t *testing.T) {
+			s.run(t, newRepo(t))
+		})
+	}
+}
+
+// RunConcurrency hammers admission, placement bookkeeping and versioned saves from many goroutines
+func RunConcurrency(t *testing.T, repo GameRepository) {
+	ctx := context.Background()
+
+	t.Run("distinct players join", func(t *testing.T) {
+		counts := hammer(t, func(i int) (repository.AdmitResult, error) {
+			return repo.AddPlayerToGame(ctx, "join", fmt.Sprintf("player-%d", i))
+		})
+		expectCounts(t, counts, map[repository.AdmitStatus]int{
+			repository.Admitted: 2,
+			repository.Full:     goroutines - 2,
+		})
+
+		players, err := repo.GetPlayers(ctx, "join")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(players) != 2 {
+			t.Errorf("players: expected 2, got %d", len(players))
+		}
+	})
+
+	t.Run("same player joins", func(t *testing.T) {
+		counts := hammer(t, func(i int) (repository.AdmitResult, error) {
+			return repo.AddPlayerToGame(ctx, "rejoin", "A")
+		})
+		expectCounts(t, counts, map[repository.AdmitStatus]int{
+			repository.Admitted:       1,
+			repository.AlreadyPresent: goroutines - 1,
+		})
+	})
+
+	t.Run("players place ships", func(t *testing.T) {
+		counts := hammer(t, func(i int) (repository.AdmitResult, error) {
+			return repo.AddPlayerShip(ctx, "place", []string{"A", "B"}[i%2])
+		})
+		expectCounts(t, counts, map[repository.AdmitStatus]int{
+			repository.Admitted:       2,
+			repository.AlreadyPresent: goroutines - 2,
+		})
+
+		res, err := repo.AddPlayerShip(ctx, "place", "C")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != repository.Full {
+			t.Errorf("third player: expected %s, got %s", repository.Full, res.Status)
+		}
+	})
+
+	t.Run("versioned saves", func(t *testing.T) {
+		game := domain.NewGame("A", "B", "save", domain.DefaultRuleSet())
+		if err := repo.SaveGame(ctx, game); err != nil {
+			t.Fatal(err)
+		}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		saved, conflicts := 0, 0
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				loaded, err := repo.GetGame(ctx, "save")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				// everyone read the same version, only one save may go through
+				loaded.Version = game.Version
+				err = repo.SaveGame(ctx, loaded)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					saved++
+				case errors.Is(err, domain.ErrVersionConflict):
+					conflicts++
+				default:
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if saved != 1 || conflicts != goroutines-1 {
+			t.Errorf("saves: expected 1 saved and %d conflicts, got %d and %d", goroutines-1, saved, conflicts)
+		}
+
+		stored, err := repo.GetGame(ctx, "save")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Version != game.Version+1 {
+			t.Errorf("version: expected %d, got %d", game.Version+1, stored.Version)
+		}
+	})
+}
+
+// RunRooms checks the room record round trip and its lifecycle
+func RunRooms(t *testing.T, repo RoomRepository) {
+	ctx := context.Background()
+
+	rules := domain.DefaultRuleSet()
+	rules.Width = 8
+	room := domain.NewRoom("lobby", "A", rules, domain.VisibilityPublic)
+	if err := repo.SaveRoom(ctx, room); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.RoomExists(ctx, "lobby") {
+		t.Fatal("expected the room to exist")
+	}
+
+	if err := repo.SetRoomStatus(ctx, "lobby", domain.RoomPlaying); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.GetRoom(ctx, "lobby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != domain.RoomPlaying || stored.Creator != "A" || stored.Visibility != domain.VisibilityPublic {
+		t.Errorf("room: got %+v", stored)
+	}
+	if left := time.Until(time.UnixMilli(stored.ExpiresAt)); left <= 0 || left > domain.RoomTTL {
+		t.Errorf("expiresAt: expected within %v, got %v", domain.RoomTTL, left)
+	}
+
+	got, err := repo.GetRules(ctx, "lobby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Width != 8 {
+		t.Errorf("rules width: expected 8, got %d", got.Width)
+	}
+
+	if err := repo.DeleteRoom(ctx, "lobby"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetRoom(ctx, "lobby"); !errors.Is(err, domain.ErrRoomNotFound) {
+		t.Errorf("deleted room: expected %v, got %v", domain.ErrRoomNotFound, err)
+	}
+	if err := repo.SetRoomStatus(ctx, "lobby", domain.RoomFinished); err != nil {
+		t.Errorf("status of a missing room: expected no error, got %v", err)
+	}
+}
+
+// RunChat checks that the chat of a room is bounded and pages back in order
+func RunChat(t *testing.T, rooms RoomRepository, repo ChatRepository) {
+	ctx := context.Background()
+
+	total := domain.ChatHistoryLimit + 10
+	for i := 0; i < total; i++ {
+		msg := domain.NewChatMessage("A", fmt.Sprint(i))
+		if err := repo.SaveChat(ctx, "lobby", &msg, domain.RoomTTL); err != nil {
+			t.Fatal(err)
+		}
+		if msg.ID == "" {
+			t.Fatal("expected the message to get an ID")
+		}
+	}
+
+	var read []domain.ChatMessage
+	before := ""
+	for {
+		page, err := repo.GetChat(ctx, "lobby", before, domain.ChatPageSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		read = append(page, read...)
+		before = page[0].ID
+	}
+
+	if len(read) != domain.ChatHistoryLimit {
+		t.Fatalf("messages: expected %d, got %d", domain.ChatHistoryLimit, len(read))
+	}
+	for i, msg := range read {
+		if want := fmt.Sprint(total - domain.ChatHistoryLimit + i); msg.Message != want {
+			t.Fatalf("message %d: expected %s, got %s", i, want, msg.Message)
+		}
+	}
+
+	if msg, err := repo.GetChatMessage(ctx, "lobby", read[0].ID); err != nil || msg.Message != read[0].Message {
+		t.Errorf("message %s: expected %q, got %v %v", read[0].ID, read[0].Message, msg, err)
+	}
+	if _, err := repo.GetChatMessage(ctx, "lobby", "1-0"); !errors.Is(err, domain.ErrChatNotFound) {
+		t.Errorf("missing message: expected %v, got %v", domain.ErrChatNotFound, err)
+	}
+
+	for want := int64(1); want <= 3; want++ {
+		if got, err := repo.CountChat(ctx, "lobby", "A"); err != nil || got != want {
+			t.Fatalf("chat count: expected %d, got %d %v", want, got, err)
+		}
+	}
+
+	repo.SetChatMute(ctx, "lobby", "A", "B", true, domain.RoomTTL)
+	repo.SetChatMute(ctx, "lobby", "A", "C", true, domain.RoomTTL)
+	repo.SetChatMute(ctx, "lobby", "A", "C", false, domain.RoomTTL)
+	if muted, _ := repo.GetChatMutes(ctx, "lobby", "A"); len(muted) != 1 || muted[0] != "B" {
+		t.Errorf("mutes: expected [B], got %v", muted)
+	}
+
+	if err := rooms.DeleteRoom(ctx, "lobby"); err != nil {
+		t.Fatal(err)
+	}
+	if page, _ := repo.GetChat(ctx, "lobby", "", domain.ChatPageSize); len(page) != 0 {
+		t.Errorf("chat of a deleted room: expected none, got %d messages", len(page))
+	}
+}
+
+// RunQueue checks that a queued player keeps its place and is claimed for one match only
+func RunQueue(t *testing.T, repo QueueRepository) {
+	ctx := context.Background()
+
+	first := mustEntry(t, "A")
+	if added, err := repo.Enqueue(ctx, first); err != nil || !added {
+		t.Fatalf("enqueue: expected added, got %v %v", added, err)
+	}
+	again := first
+	again.EnqueuedAt++
+	if added, _ := repo.Enqueue(ctx, again); added {
+		t.Error("enqueue again: expected the player to keep its place")
+	}
+	if entries, _ := repo.QueueEntries(ctx); len(entries) != 1 || entries[0] != first {
+		t.Errorf("entries: expected [%+v], got %+v", first, entries)
+	}
+
+	if claimed, _ := repo.ClaimMatch(ctx, "A", "B"); claimed {
+		t.Fatal("claim: expected no match while B is not queued")
+	}
+	if entries, _ := repo.QueueEntries(ctx); len(entries) != 1 {
+		t.Fatalf("entries after a failed claim: expected A to stay, got %+v", entries)
+	}
+
+	// players queued at once are matched once, whoever claims them first
+	for _, p := range []string{"B", "C", "D"} {
+		repo.Enqueue(ctx, mustEntry(t, p))
+	}
+	var claims atomic.Int64
+	var wg sync.WaitGroup
+	for _, pair := range [][2]string{{"A", "B"}, {"B", "C"}, {"C", "D"}, {"A", "D"}} {
+		wg.Add(1)
+		go func(a, b string) {
+			defer wg.Done()
+			if claimed, err := repo.ClaimMatch(ctx, a, b); err == nil && claimed {
+				claims.Add(1)
+			}
+		}(pair[0], pair[1])
+	}
+	wg.Wait()
+
+	entries, _ := repo.QueueEntries(ctx)
+	if int(claims.Load())*2+len(entries) != 4 {
+		t.Errorf("claims: %d matches and %d players left of 4", claims.Load(), len(entries))
+	}
+
+	for _, e := range entries {
+		if left, _ := repo.Dequeue(ctx, e.PlayerID); !left {
+			t.Errorf("dequeue %s: expected it to be queued", e.PlayerID)
+		}
+	}
+	if left, _ := repo.Dequeue(ctx, "A"); left {
+		t.Error("dequeue: expected A to be out of the queue")
+	}
+}
+
+func mustEntry(t *testing.T, playerID string) domain.QueueEntry {
+	t.Helper()
+	e, err := domain.NewQueueEntry(playerID, domain.DefaultRating, "eu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// RunLeaderboard checks that results land on the right boards and scopes, the streak board
+// keeps the longest streak and every season starts exactly once
+func RunLeaderboard(t *testing.T, repo LeaderboardRepository) {
+	ctx := context.Background()
+
+	results := []struct {
+		player string
+		rating int
+		won    bool
+	}{
+		{"A", 1220, true}, {"B", 1180, false},
+		{"A", 1238, true}, {"C", 1182, false},
+		{"A", 1220, false}, {"B", 1198, true},
+	}
+	for _, r := range results {
+		if err := repo.RecordResult(ctx, domain.GlobalScope, r.player, r.rating, r.won); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo.RecordResult(ctx, "season-s2", "C", 1200, true)
+
+	for board, want := range map[domain.Board][]string{
+		domain.BoardRating: {"A", "B", "C"},
+		domain.BoardWins:   {"A", "B", "C"},
+		domain.BoardStreak: {"A", "B", "C"},
+	} {
+		entries, total, err := repo.GetLeaderboard(ctx, domain.GlobalScope, board, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.PlayerID)
+		}
+		if total != 3 || !slices.Equal(got, want) {
+			t.Errorf("%s board: expected %v, got %v of %d", board, want, got, total)
+		}
+	}
+
+	// A lost the last game, the longest streak of 2 stays
+	streak, err := repo.GetLeaderboardRank(ctx, domain.GlobalScope, domain.BoardStreak, "A")
+	if err != nil || streak.Rank != 1 || streak.Score != 2 {
+		t.Errorf("streak of A: got %+v %v", streak, err)
+	}
+	wins, err := repo.GetLeaderboardRank(ctx, domain.GlobalScope, domain.BoardWins, "C")
+	if err != nil || wins.Rank != 3 || wins.Score != 0 {
+		t.Errorf("wins of C: got %+v %v", wins, err)
+	}
+
+	page, total, err := repo.GetLeaderboard(ctx, domain.GlobalScope, domain.BoardRating, 1, 1)
+	if err != nil || total != 3 || len(page) != 1 || page[0].PlayerID != "B" || page[0].Rank != 2 || page[0].Score != 1198 {
+		t.Errorf("second page: got %+v of %d %v", page, total, err)
+	}
+	if page, _, _ := repo.GetLeaderboard(ctx, domain.GlobalScope, domain.BoardRating, 5, 10); len(page) != 0 {
+		t.Errorf("page past the end: expected no entries, got %+v", page)
+	}
+
+	season, _, err := repo.GetLeaderboard(ctx, "season-s2", domain.BoardRating, 0, 10)
+	if err != nil || len(season) != 1 || season[0].PlayerID != "C" {
+		t.Errorf("season board: got %+v %v", season, err)
+	}
+	if _, err := repo.GetLeaderboardRank(ctx, "season-s2", domain.BoardRating, "A"); !errors.Is(err, domain.ErrNotRanked) {
+		t.Errorf("rank outside the season: expected %v, got %v", domain.ErrNotRanked, err)
+	}
+
+	var claims atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, err := repo.ClaimSeasonStart(ctx, "s2"); err == nil && ok {
+				claims.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if claims.Load() != 1 {
+		t.Errorf("season start: expected 1 claim, got %d", claims.Load())
+	}
+	if err := repo.ReleaseSeasonStart(ctx, "s2"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := repo.ClaimSeasonStart(ctx, "s2"); err != nil || !ok {
+		t.Errorf("claim after the release: expected it to succeed, got %v %v", ok, err)
+	}
+
+	// the scores are halved towards the default once, however often the reset runs
+	before, _, _ := repo.GetLeaderboard(ctx, domain.GlobalScope, domain.BoardRating, 0, 10)
+	for range 2 {
+		if err := repo.SoftResetRatingBoard(ctx, domain.GlobalScope, "s2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range before {
+		want := domain.Rating{Points: e.Score}.SoftReset().Points
+		if got, err := repo.GetLeaderboardRank(ctx, domain.GlobalScope, domain.BoardRating, e.PlayerID); err != nil || got.Score != want {
+			t.Errorf("rating of %s after the reset: expected %d, got %+v %v", e.PlayerID, want, got, err)
+		}
+	}
+}
+
+// RunTournaments checks that tournaments round trip and that of two saves of the same
+// version only the first one succeeds
+func RunTournaments(t *testing.T, rooms RoomRepository, repo TournamentRepository) {
+	ctx := context.Background()
+
+	if _, err := repo.GetTournament(ctx, "missing"); !errors.Is(err, domain.ErrTournamentNotFound) {
+		t.Errorf("missing tournament: expected %v, got %v", domain.ErrTournamentNotFound, err)
+	}
+
+	tour, err := domain.NewTournament("t1", "Cup", "org", domain.DoubleElimination, 4, domain.DefaultRuleSet(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"A", "B", "C"} {
+		tour.Register(p)
+	}
+	tour.Start()
+	if err := repo.SaveTournament(ctx, tour); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := repo.GetTournament(ctx, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := repo.GetTournament(ctx, "t1")
+	if len(first.Matches) != len(tour.Matches) || first.Version != tour.Version || first.Match("W1-1").Players != tour.Match("W1-1").Players {
+		t.Errorf("round trip: expected %+v, got %+v", tour, first)
+	}
+
+	first.Report("W1-2", "B")
+	if err := repo.SaveTournament(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	second.Report("W1-2", "C")
+	if err := repo.SaveTournament(ctx, second); !errors.Is(err, domain.ErrTournamentConflict) {
+		t.Errorf("stale save: expected %v, got %v", domain.ErrTournamentConflict, err)
+	}
+
+	stored, _ := repo.GetTournament(ctx, "t1")
+	if stored.Match("W1-2").Winner != "B" {
+		t.Errorf("expected the first save to stay, got %+v", stored.Match("W1-2"))
+	}
+
+	// the room of a match waits for its players as long as the tournament is kept,
+	// playing in it does not cut that short
+	room := domain.NewRoom("t1-W1-1", "org", domain.DefaultRuleSet(), domain.VisibilityPrivate)
+	room.Tournament = "t1"
+	if err := rooms.SaveRoom(ctx, room); err != nil {
+		t.Fatal(err)
+	}
+	rooms.TouchRoom(ctx, room.ID)
+	matchRoom, err := rooms.GetRoom(ctx, room.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left := time.Until(time.UnixMilli(matchRoom.ExpiresAt)); left <= domain.RoomTTL || left > domain.TournamentTTL {
+		t.Errorf("match room expiry: expected up to %v, got %v", domain.TournamentTTL, left)
+	}
+}
+
+// RunLobby checks that only public rooms are listed, newest first, and that deleted
+// rooms leave the lobby
+func RunLobby(t *testing.T, rooms RoomRepository, repo LobbyRepository) {
+	ctx := context.Background()
+
+	for i, id := range []string{"old", "hidden", "new"} {
+		visibility := domain.VisibilityPublic
+		if id == "hidden" {
+			visibility = domain.VisibilityPrivate
+		}
+		room := domain.NewRoom(id, "host", domain.DefaultRuleSet(), visibility)
+		room.CreatedAt += int64(i)
+		if err := rooms.SaveRoom(ctx, room); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids, total, err := repo.PublicRooms(ctx, 0, 10)
+	if err != nil || total != 2 || !slices.Equal(ids, []string{"new", "old"}) {
+		t.Errorf("public rooms: got %v of %d %v", ids, total, err)
+	}
+	if ids, _, _ := repo.PublicRooms(ctx, 1, 1); !slices.Equal(ids, []string{"old"}) {
+		t.Errorf("second page: got %v", ids)
+	}
+
+	if err := rooms.DeleteRoom(ctx, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := repo.RemovePublicRoom(ctx, "old"); err != nil || !ok {
+		t.Errorf("remove listed room: got %v %v", ok, err)
+	}
+	if ok, _ := repo.RemovePublicRoom(ctx, "hidden"); ok {
+		t.Error("remove private room: expected it not to be listed")
+	}
+	if ids, total, _ := repo.PublicRooms(ctx, 0, 10); total != 0 || len(ids) != 0 {
+		t.Errorf("lobby after removal: got %v of %d", ids, total)
+	}
+}
@@ -0,0 +1,105 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// UserRepository is what RunUsers needs, services.UserRepository can not be named
+// here without an import cycle
+type UserRepository interface {
+	CreateUser(ctx context.Context, u *domain.User) error
+	GetUser(ctx context.Context, id string) (*domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
+	UpdateProfile(ctx context.Context, id string, displayName string, avatarURL string) error
+	AddRatingResult(ctx context.Context, userID string, delta int, won bool) (domain.Rating, error)
+	SoftResetRatings(ctx context.Context) error
+}
+
+// RunUsers checks that users round trip with their password hash, usernames stay unique
+// and concurrent rating results all count
+func RunUsers(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+
+	ace, err := domain.NewUser("user-1", "Ace", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ace.PasswordHash = []byte("hash")
+	if err := repo.CreateUser(ctx, ace); err != nil {
+		t.Fatal(err)
+	}
+
+	again, _ := domain.NewUser("user-2", "ace", "", "")
+	if err := repo.CreateUser(ctx, again); !errors.Is(err, domain.ErrUsernameTaken) {
+		t.Errorf("same username: expected %v, got %v", domain.ErrUsernameTaken, err)
+	}
+
+	stored, err := repo.GetUserByUsername(ctx, "ace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ID != "user-1" || string(stored.PasswordHash) != "hash" || stored.DisplayName != "ace" {
+		t.Errorf("user: got %+v", stored)
+	}
+
+	if err := repo.UpdateProfile(ctx, "user-1", "Ace of Space", "https://example.com/ace.png"); err != nil {
+		t.Fatal(err)
+	}
+	stored, err = repo.GetUser(ctx, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.DisplayName != "Ace of Space" || stored.AvatarURL != "https://example.com/ace.png" || string(stored.PasswordHash) != "hash" {
+		t.Errorf("updated user: got %+v", stored)
+	}
+
+	// results of games ending at the same time all count
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(won bool) {
+			defer wg.Done()
+			if _, err := repo.AddRatingResult(ctx, "user-1", 10, won); err != nil {
+				t.Error(err)
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+
+	rating, err := repo.AddRatingResult(ctx, "user-1", -60, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := domain.Rating{Points: domain.DefaultRating + 100, Games: 17, Wins: 8, Losses: 9}
+	if rating != want {
+		t.Errorf("rating: expected %+v, got %+v", want, rating)
+	}
+
+	// a new season pulls the rating halfway back and keeps the games
+	if err := repo.SoftResetRatings(ctx); err != nil {
+		t.Fatal(err)
+	}
+	stored, err = repo.GetUser(ctx, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := want.SoftReset(); stored.Rating != want {
+		t.Errorf("soft reset: expected %+v, got %+v", want, stored.Rating)
+	}
+
+	if _, err := repo.AddRatingResult(ctx, "user-2", 10, true); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("rating of missing user: expected %v, got %v", domain.ErrUserNotFound, err)
+	}
+
+	if _, err := repo.GetUser(ctx, "user-2"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("missing user: expected %v, got %v", domain.ErrUserNotFound, err)
+	}
+	if err := repo.UpdateProfile(ctx, "user-2", "B", ""); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("update missing user: expected %v, got %v", domain.ErrUserNotFound, err)
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// SessionTTL is how long a session token is valid
+const SessionTTL = 24 * time.Hour
+
+const (
+	tokenIssuer   = "space-striker"
+	maxNameLength = 32
+)
+
+var (
+	ErrInvalidToken = errors.New("Invalid or expired session token")
+	ErrInvalidName  = errors.New("Name must be at most 32 characters")
+)
+
+// SessionClaims are the claims of a session token, the subject is the player id
+type SessionClaims struct {
+	Name  string `json:"name,omitempty"`
+	Guest bool   `json:"guest"`
+	jwt.RegisteredClaims
+}
+
+// AuthService issues and verifies the HMAC signed session tokens players connect with,
+// every server of a cluster must share the secret.
+type AuthService struct {
+	secret []byte
+	users  UserRepository
+}
+
+func NewAuthService(secret []byte, users UserRepository) *AuthService {
+	return &AuthService{
+		secret: secret,
+		users:  users,
+	}
+}
+
+// GuestSession gives a new guest player a token
+func (as *AuthService) GuestSession(name string) (models.SessionResponse, error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return models.SessionResponse{}, ErrInvalidName
+	}
+	return as.issue("guest-"+uuid.NewString(), name, true)
+}
+
+func (as *AuthService) issue(playerID string, name string, guest bool) (models.SessionResponse, error) {
+	now := time.Now()
+	expires := now.Add(SessionTTL)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, SessionClaims{
+		Name:  name,
+		Guest: guest,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   playerID,
+			Issuer:    tokenIssuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	})
+	signed, err := token.SignedString(as.secret)
+	if err != nil {
+		return models.SessionResponse{}, err
+	}
+
+	return models.SessionResponse{
+		Token:     signed,
+		PlayerID:  playerID,
+		Name:      name,
+		Guest:     guest,
+		ExpiresAt: expires.UnixMilli(),
+	}, nil
+}
+
+// VerifyToken checks the signature and expiry of token and returns its claims
+func (as *AuthService) VerifyToken(token string) (*SessionClaims, error) {
+	claims := &SessionClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return as.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// TokenFromRequest reads the session token from the Authorization header, browsers can
+// not set headers on a WebSocket upgrade so the token query parameter works too
+func TokenFromRequest(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return r.URL.Query().Get("token")
+}
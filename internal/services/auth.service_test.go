@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/boltdb"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSessionToken(t *testing.T) {
+	as := NewAuthService([]byte("secret"), nil)
+
+	session, err := as.GuestSession("  Ace ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(session.PlayerID, "guest-") || session.Name != "Ace" || !session.Guest {
+		t.Errorf("session: got %+v", session)
+	}
+
+	claims, err := as.VerifyToken(session.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != session.PlayerID || claims.Name != "Ace" {
+		t.Errorf("claims: expected %s Ace, got %s %s", session.PlayerID, claims.Subject, claims.Name)
+	}
+
+	if _, err := NewAuthService([]byte("other"), nil).VerifyToken(session.Token); err != ErrInvalidToken {
+		t.Errorf("other secret: expected %v, got %v", ErrInvalidToken, err)
+	}
+
+	// a token without signature must never pass for any player
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, err := as.VerifyToken(unsigned); err != ErrInvalidToken {
+		t.Errorf("unsigned token: expected %v, got %v", ErrInvalidToken, err)
+	}
+
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	expired, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if _, err := as.VerifyToken(expired); err != ErrInvalidToken {
+		t.Errorf("expired token: expected %v, got %v", ErrInvalidToken, err)
+	}
+
+	if _, err := as.GuestSession(strings.Repeat("a", maxNameLength+1)); err != ErrInvalidName {
+		t.Errorf("long name: expected %v, got %v", ErrInvalidName, err)
+	}
+}
+
+func TestTokenFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/ws?roomID=room&token=query", nil)
+	if got := TokenFromRequest(r); got != "query" {
+		t.Errorf("query token: expected query, got %s", got)
+	}
+
+	r.Header.Set("Authorization", "Bearer header")
+	if got := TokenFromRequest(r); got != "header" {
+		t.Errorf("header token: expected header, got %s", got)
+	}
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	users, err := boltdb.NewBoltUserRepository(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer users.Close()
+	as := NewAuthService([]byte("secret"), users)
+
+	if _, err := as.Register(ctx, models.RegisterRequest{Username: "ace", Password: "short"}); err != domain.ErrInvalidPassword {
+		t.Errorf("short password: expected %v, got %v", domain.ErrInvalidPassword, err)
+	}
+
+	registered, err := as.Register(ctx, models.RegisterRequest{Username: "Ace", Password: "correct horse", DisplayName: "Ace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registered.Guest || registered.User == nil || string(registered.User.PasswordHash) == "correct horse" {
+		t.Errorf("registered session: got %+v", registered)
+	}
+
+	if _, err := as.Login(ctx, "ace", "wrong horse"); err != domain.ErrInvalidCredentials {
+		t.Errorf("wrong password: expected %v, got %v", domain.ErrInvalidCredentials, err)
+	}
+	if _, err := as.Login(ctx, "nobody", "correct horse"); err != domain.ErrInvalidCredentials {
+		t.Errorf("unknown user: expected %v, got %v", domain.ErrInvalidCredentials, err)
+	}
+
+	session, err := as.Login(ctx, "ACE", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.PlayerID != registered.PlayerID {
+		t.Errorf("player id: expected the same id on every login, got %s and %s", registered.PlayerID, session.PlayerID)
+	}
+
+	claims, _ := as.VerifyToken(session.Token)
+	bad := "ftp://example.com/ace.png"
+	if _, err := as.UpdateProfile(ctx, claims, models.ProfileRequest{AvatarURL: &bad}); err != domain.ErrInvalidAvatarURL {
+		t.Errorf("avatar: expected %v, got %v", domain.ErrInvalidAvatarURL, err)
+	}
+
+	guest, _ := as.GuestSession("")
+	guestClaims, _ := as.VerifyToken(guest.Token)
+	name := "Guest"
+	if _, err := as.UpdateProfile(ctx, guestClaims, models.ProfileRequest{DisplayName: &name}); err != ErrGuestProfile {
+		t.Errorf("guest profile: expected %v, got %v", ErrGuestProfile, err)
+	}
+}
+
+func TestRankedGameOver(t *testing.T) {
+	ctx := context.Background()
+	users, err := boltdb.NewBoltUserRepository(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer users.Close()
+
+	for _, id := range []string{"A", "B"} {
+		u, _ := domain.NewUser(id, "player_"+strings.ToLower(id), "", "")
+		if err := users.CreateUser(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gs, _, _ := startedGame(t)
+	gs.SetUsers(users)
+	gs.HandleDisconnect("room", "A")
+
+	as := NewAuthService([]byte("secret"), users)
+	for player, want := range map[string]domain.Rating{
+		"A": {Points: domain.DefaultRating - 20, Games: 1, Losses: 1},
+		"B": {Points: domain.DefaultRating + 20, Games: 1, Wins: 1},
+	} {
+		got, err := as.GetRating(ctx, player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Rating != want {
+			t.Errorf("rating of %s: expected %+v, got %+v", player, want, got.Rating)
+		}
+	}
+
+	// the game is over already, a late disconnect must not rate it twice
+	gs.HandleDisconnect("room", "B")
+	if got, _ := as.GetRating(ctx, "B"); got.Games != 1 {
+		t.Errorf("games of B: expected 1, got %d", got.Games)
+	}
+
+	if _, err := as.GetRating(ctx, "guest-1"); err != domain.ErrUserNotFound {
+		t.Errorf("guest rating: expected %v, got %v", domain.ErrUserNotFound, err)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	users, err := boltdb.NewBoltUserRepository(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer users.Close()
+
+	for _, id := range []string{"A", "B"} {
+		u, _ := domain.NewUser(id, "player_"+strings.ToLower(id), "", "")
+		if err := users.CreateUser(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	seasons := []domain.Season{
+		{ID: "s1", Start: now.Add(-48 * time.Hour), End: now.Add(-time.Hour)},
+		{ID: "s2", Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+	}
+
+	gs, repo, _ := startedGame(t)
+	gs.SetUsers(users)
+	lb := NewLeaderboard(repo, users, seasons)
+	gs.SetLeaderboard(lb)
+	gs.HandleDisconnect("room", "A")
+
+	for _, season := range []string{SeasonCurrent, SeasonGlobal} {
+		page, err := lb.Page(ctx, "rating", season, 0, 0, "A")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Total != 2 || page.Entries[0].PlayerID != "B" || page.Entries[0].Score != domain.DefaultRating+20 {
+			t.Errorf("%s rating board: got %+v", season, page)
+		}
+		if page.Entries[0].Name != "player_b" || page.Me == nil || page.Me.Rank != 2 {
+			t.Errorf("%s rating board: expected names and the place of A, got %+v", season, page)
+		}
+	}
+
+	// the archived season has no games
+	if page, err := lb.Page(ctx, "wins", "s1", 0, 0, ""); err != nil || page.Total != 0 || page.Season.ID != "s1" {
+		t.Errorf("past season: got %+v %v", page, err)
+	}
+	if _, err := lb.Page(ctx, "rating", "s9", 0, 0, ""); err != domain.ErrSeasonNotFound {
+		t.Errorf("unknown season: expected %v, got %v", domain.ErrSeasonNotFound, err)
+	}
+	if _, err := lb.Page(ctx, "losses", "", 0, 0, ""); err != domain.ErrInvalidBoard {
+		t.Errorf("unknown board: expected %v, got %v", domain.ErrInvalidBoard, err)
+	}
+
+	// a failed rollover is tried again on the next check
+	lb.users = &failingReset{UserRepository: users, fails: 1}
+	lb.startSeason(ctx, now)
+	if b, _ := users.GetUser(ctx, "B"); b.Rating.Points != domain.DefaultRating+20 {
+		t.Errorf("rating of B after a failed rollover: expected it unchanged, got %d", b.Rating.Points)
+	}
+
+	// the rollover into s2 resets the ratings and the global board once, however often it is seen
+	lb.startSeason(ctx, now)
+	lb.startSeason(ctx, now)
+	want := domain.DefaultRating + 10
+	b, _ := users.GetUser(ctx, "B")
+	if b.Rating.Points != want {
+		t.Errorf("rating of B after the rollover: expected %d, got %d", want, b.Rating.Points)
+	}
+	if page, _ := lb.Page(ctx, "rating", SeasonGlobal, 0, 0, ""); page.Entries[0].Score != want {
+		t.Errorf("global board after the rollover: expected B at %d, got %+v", want, page.Entries[0])
+	}
+}
+
+// failingReset fails the first soft resets of the ratings
+type failingReset struct {
+	UserRepository
+	fails int
+}
+
+func (f *failingReset) SoftResetRatings(ctx context.Context) error {
+	if f.fails > 0 {
+		f.fails--
+		return errors.New("users are down")
+	}
+	return f.UserRepository.SoftResetRatings(ctx)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/bot"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// botThinkTime is how long the bot waits before firing, it stays well inside the smallest turn limit
+const botThinkTime = 1500 * time.Millisecond
+
+// seatBot adds the bot as the second player of a bot room
+func (gs *GameService) seatBot(ctx context.Context, roomID string) (repository.AdmitResult, error) {
+	return gs.repo.AddPlayerToGame(ctx, roomID, bot.PlayerID(roomID))
+}
+
+// placeBotShips places a random fleet for the bot as soon as the game is created
+// and returns the placement event to save with the game.
+func (gs *GameService) placeBotShips(game *domain.Game) (domain.Event, error) {
+	botID := bot.PlayerID(game.ID)
+	placed := domain.NewEvent(domain.EventPlace, botID)
+	ships, err := bot.PlaceFleet(game.Rules)
+	if err != nil {
+		return placed, err
+	}
+	placed.Ships = ships
+
+	err = game.AddShip(botID, placed.Ships)
+	return placed, err
+}
+
+// markBotPlaced records the bot fleet in the placement bookkeeping once the game is saved
+func (gs *GameService) markBotPlaced(ctx context.Context, roomID string) {
+	if placed, err := gs.repo.AddPlayerShip(ctx, roomID, bot.PlayerID(roomID)); err != nil || placed.Status != repository.Admitted {
+		log.Printf("Failed to mark bot ships as placed in %s, status : %v, err : %v", roomID, placed.Status, err)
+	}
+}
+
+// scheduleBotTurn lets the bot play when it is its turn, the move goes through the same
+// handlers and timers as a human move.
+func (gs *GameService) scheduleBotTurn(game *domain.Game) {
+	if game.Status != domain.StatusActive || !bot.IsBot(game.ActivePlayer) {
+		return
+	}
+
+	go func() {
+		time.Sleep(botThinkTime)
+		gs.playBotTurn(game.ID)
+	}()
+}
+
+func (gs *GameService) playBotTurn(roomID string) {
+	ctx := context.Background()
+
+	game, err := gs.repo.GetGame(ctx, roomID)
+	if err != nil {
+		log.Printf("bot failed to get game %s, err : %v", roomID, err)
+		return
+	}
+
+	if game.Status != domain.StatusActive || !bot.IsBot(game.ActivePlayer) {
+		return
+	}
+
+	botID := game.ActivePlayer
+	difficulty, _ := gs.repo.GetRoomBot(ctx, roomID)
+	d, err := bot.ParseDifficulty(difficulty)
+	if err != nil {
+		d = bot.HuntTarget
+	}
+
+	strategy := bot.New(d)
+	view := bot.View{
+		Board: game.HideOpponentShips(botID),
+		Sunk:  game.SunkShips(game.GetOpponent(botID)),
+		Fleet: game.Rules.Fleet,
+	}
+
+	if game.Rules.Mode == domain.ModeSalvo {
+		shots := bot.Salvo(strategy, view, game.SalvoSize(botID))
+		gs.HandleSalvo(ctx, botID, roomID, toRawMessage(models.SalvoPayload{Shots: shots}))
+		return
+	}
+
+	shot := strategy.NextShot(view)
+	gs.HandleMove(ctx, botID, roomID, toRawMessage(models.MovePayload(shot)))
+}
@@ -3,20 +3,221 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
 )
 
+var (
+	ErrInvalidMute      = errors.New("Only another player of the room can be muted")
+	ErrReportOwnMessage = errors.New("You can not report your own message")
+)
+
+// HandleChat checks the length and rate of the message, masks blocked words, stores it
+// and delivers it to the room, players who muted the sender do not get it.
 func (gs *GameService) HandleChat(ctx context.Context,playerId string, roomId string,payload json.RawMessage)  {
 	var msgpayload models.ChatPayload
 
 	if err := json.Unmarshal(payload,&msgpayload); err != nil {
 		log.Println("error while parsing chat message : ",err)
+		gs.sendError("Invalid chat message",playerId)
+		return
+	}
+	text := strings.TrimSpace(msgpayload.Message)
+	if text == "" {
+		return
+	}
+	if utf8.RuneCountInString(text) > domain.MaxChatLength {
+		gs.sendError(domain.ErrChatTooLong.Error(),playerId)
+		return
+	}
+
+	sent,err := gs.repo.CountChat(ctx,roomId,playerId)
+	if err != nil {
+		log.Printf("Failed to count chat of %s in room %s, err : %v",playerId,roomId,err)
+		return
+	}
+	if sent > domain.ChatRateLimit {
+		gs.sendError(domain.ErrChatRateLimited.Error(),playerId)
+		return
+	}
+
+	msg := domain.NewChatMessage(playerId,gs.chatFilter.Mask(text))
+	if err := gs.repo.SaveChat(ctx,roomId,&msg,gs.roomTTL(ctx,roomId)); err != nil {
+		// the message still reaches the room, it is only missing from the history
+		log.Printf("Failed to save chat of room %s, err : %v",roomId,err)
+	}
+
+	gs.deliverChat(ctx,roomId,msg)
+
+}
+
+// deliverChat broadcasts msg to the room unless a player muted its sender,
+// then every other player gets it on their own and spectators on their channel
+func (gs *GameService) deliverChat(ctx context.Context,roomId string,msg domain.ChatMessage)  {
+	players,err := gs.repo.GetPlayers(ctx,roomId)
+	if err != nil {
+		log.Printf("Failed to get players of room %s, err : %v",roomId,err)
+	}
+
+	var receivers []string
+	for _, p := range players {
+		if !gs.hasMuted(ctx,roomId,p,msg.Sender) {
+			receivers = append(receivers,p)
+		}
+	}
+	if len(receivers) == len(players) {
+		gs.SendToRoom(roomId,models.TypeChat,msg)
+		return
+	}
+
+	for _, p := range receivers {
+		gs.SendToSolo(ctx,p,models.TypeChat,msg)
 	}
+	gs.SendToSpectators(roomId,models.TypeChat,msg)
+}
 
-	msgpayload.Sender = playerId
+// roomTTL is how long the chat of the room is kept, tournament rooms live longer than the others
+func (gs *GameService) roomTTL(ctx context.Context,roomId string) time.Duration {
+	room,err := gs.repo.GetRoom(ctx,roomId)
+	if err != nil {
+		return domain.RoomTTL
+	}
+	return room.TTL()
+}
+
+func (gs *GameService) hasMuted(ctx context.Context,roomId string,playerId string,sender string) bool {
+	muted,err := gs.repo.GetChatMutes(ctx,roomId,playerId)
+	if err != nil {
+		log.Printf("Failed to get chat mutes of %s, err : %v",playerId,err)
+		return false
+	}
+	return slices.Contains(muted,sender)
+}
 
-	gs.SendToRoom(roomId,models.TypeChat,msgpayload)
+// HandleChatMute mutes or unmutes another player of the room for the sender, the sender gets the payload back
+func (gs *GameService) HandleChatMute(ctx context.Context,playerId string,roomId string,payload json.RawMessage)  {
+	var mute models.ChatMutePayload
+	if err := json.Unmarshal(payload,&mute); err != nil {
+		gs.sendError("Invalid mute request",playerId)
+		return
+	}
+	if mute.Player == playerId || !gs.repo.FindPlayer(ctx,roomId,mute.Player) {
+		gs.sendError(ErrInvalidMute.Error(),playerId)
+		return
+	}
 
-}
\ No newline at end of file
+	if err := gs.repo.SetChatMute(ctx,roomId,playerId,mute.Player,mute.Muted,gs.roomTTL(ctx,roomId)); err != nil {
+		log.Printf("Failed to mute %s for %s, err : %v",mute.Player,playerId,err)
+		gs.sendError("Failed to mute player",playerId)
+		return
+	}
+	gs.SendToSolo(ctx,playerId,models.TypeChatMute,mute)
+}
+
+// HandleChatReport files a chat message of the room for moderator review, the sender gets the payload back
+func (gs *GameService) HandleChatReport(ctx context.Context,playerId string,roomId string,payload json.RawMessage)  {
+	var report models.ChatReportPayload
+	if err := json.Unmarshal(payload,&report); err != nil || !domain.ValidChatID(report.MessageID) {
+		gs.sendError(domain.ErrInvalidChatID.Error(),playerId)
+		return
+	}
+
+	msg,err := gs.repo.GetChatMessage(ctx,roomId,report.MessageID)
+	if err != nil {
+		if !errors.Is(err,domain.ErrChatNotFound) {
+			log.Printf("Failed to get chat message %s of room %s, err : %v",report.MessageID,roomId,err)
+		}
+		gs.sendError(domain.ErrChatNotFound.Error(),playerId)
+		return
+	}
+	if msg.Sender == playerId {
+		gs.sendError(ErrReportOwnMessage.Error(),playerId)
+		return
+	}
+
+	reason := []rune(strings.TrimSpace(report.Reason))
+	if len(reason) > domain.MaxChatLength {
+		reason = reason[:domain.MaxChatLength]
+	}
+	if err := gs.repo.SaveChatReport(ctx,&domain.ChatReport{
+		RoomID: roomId,
+		Reporter: playerId,
+		Reason: string(reason),
+		Message: *msg,
+		At: time.Now().UnixMilli(),
+	}); err != nil {
+		log.Printf("Failed to save chat report of %s, err : %v",playerId,err)
+		gs.sendError("Failed to report message",playerId)
+		return
+	}
+	gs.SendToSolo(ctx,playerId,models.TypeChatReport,report)
+}
+
+// SendChatHistory sends the latest chat messages of the room to a player who came back
+func (gs *GameService) SendChatHistory(ctx context.Context,playerId string,roomId string)  {
+	messages,err := gs.repo.GetChat(ctx,roomId,"",domain.ChatPageSize)
+	if err != nil {
+		log.Printf("Failed to get chat of room %s, err : %v",roomId,err)
+		return
+	}
+	if len(messages) == 0 {
+		return
+	}
+	page := chatPage(messages)
+	page.Messages = hideMuted(ctx,gs.repo,roomId,playerId,page.Messages)
+	gs.SendToSolo(ctx,playerId,models.TypeChatHistory,page)
+}
+
+// ChatHistory returns the page of chat messages sent before the message with ID before,
+// an empty before gives the latest page. Only the players of the room can read its chat.
+func (hs HttpService) ChatHistory(ctx context.Context,roomID string,playerID string,before string) (models.ChatHistoryPayload,error) {
+	if before != "" && !domain.ValidChatID(before) {
+		return models.ChatHistoryPayload{},domain.ErrInvalidChatID
+	}
+
+	if !hs.repo.RoomExists(ctx,roomID) {
+		return models.ChatHistoryPayload{},domain.ErrRoomNotFound
+	}
+	players,err := hs.repo.GetPlayers(ctx,roomID)
+	if err != nil {
+		return models.ChatHistoryPayload{},err
+	}
+	if !slices.Contains(players,playerID) {
+		return models.ChatHistoryPayload{},ErrNotInRoom
+	}
+
+	messages,err := hs.repo.GetChat(ctx,roomID,before,domain.ChatPageSize)
+	if err != nil {
+		return models.ChatHistoryPayload{},err
+	}
+	page := chatPage(messages)
+	page.Messages = hideMuted(ctx,hs.repo,roomID,playerID,page.Messages)
+	return page,nil
+}
+
+// hideMuted drops the messages of the players playerID muted, muted players stay muted in the history
+func hideMuted(ctx context.Context,repo ChatRepository,roomID string,playerID string,messages []domain.ChatMessage) []domain.ChatMessage {
+	muted,err := repo.GetChatMutes(ctx,roomID,playerID)
+	if err != nil {
+		log.Printf("Failed to get chat mutes of %s, err : %v",playerID,err)
+	}
+	return slices.DeleteFunc(messages,func(msg domain.ChatMessage) bool {
+		return slices.Contains(muted,msg.Sender)
+	})
+}
+
+func chatPage(messages []domain.ChatMessage) models.ChatHistoryPayload {
+	page := models.ChatHistoryPayload{Messages: messages}
+	// a short page is the oldest one
+	if len(messages) == domain.ChatPageSize {
+		page.NextBefore = messages[0].ID
+	}
+	return page
+}
@@ -6,31 +6,58 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/bot"
 	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
-	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/redis"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository"
 	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
 )
 
+var (
+	ErrGameNotFound = errors.New("Game Not Found")
+	ErrSaveGame     = errors.New("Failed to save the game")
+	ErrGameFull     = errors.New("Game Full")
+
+	// errNoChange tells updateGame that the game is already in the wanted state
+	errNoChange = errors.New("game unchanged")
+)
+
+// maxUpdateAttempts bounds how often a player action is retried after concurrent saves
+const maxUpdateAttempts = 5
+
 type HubInterface interface {
 	BroadcastMessage (roomID string, payload []byte)
 	SoloMessage (channel string, payload []byte)
+	SpectatorMessage (roomID string, payload []byte)
+	// LobbyMessage reaches every lobby connection of every server
+	LobbyMessage (payload []byte)
 }
 
 
 type GameService struct {
-	repo redis.RedisGameRepository
+	repo GameRepository
 	hub  HubInterface
+	chatFilter *domain.ChatFilter
+	users UserRepository
+	leaderboard *Leaderboard
+	tournaments *TournamentService
+	lobby *LobbyService
 }
 
-func NewGameService(r redis.RedisGameRepository, h HubInterface) *GameService {
+func NewGameService(r GameRepository, h HubInterface) *GameService {
 	return &GameService{
 		repo: r,
 		hub:  h,
 	}
 }
 
+// SetChatFilter masks the words of f in every chat message sent from now on
+func (gs *GameService) SetChatFilter(f *domain.ChatFilter) {
+	gs.chatFilter = f
+}
+
 // Handlers
 
 func (gs *GameService) HandleMove(ctx context.Context, playerId string, roomID string, payload json.RawMessage) {
@@ -40,43 +67,108 @@ func (gs *GameService) HandleMove(ctx context.Context, playerId string, roomID s
 		return
 	}
 
-	if err := gs.repo.LockGame(ctx,roomID);err!=nil{
+	var result domain.CellState
+	var sunk *domain.PlacedShip
+	var IsWinner bool
+
+	game, err := gs.updateGame(ctx, roomID, func(game *domain.Game) ([]domain.Event, error) {
+		// handle shot 
+		var err error
+		result, err = game.HandleShot(playerId, domain.Point(move))
+		if err != nil {
+			return nil, err
+		}
+		// check if the shot finished off a ship
+		sunk = nil
+		if result == domain.Hit {
+			if ship, ok := game.SunkShip(game.GetOpponent(playerId), domain.Point(move)); ok {
+				sunk = &ship
+			}
+		}
+
+		// check for winner
+		IsWinner = game.CheckWinner(playerId)
+		
+		// HandleShot already moved the turn according to the room rules
+		game.AddEndAt(game.Rules.TurnDuration())
+
+		shot := domain.NewEvent(domain.EventShot,playerId)
+		shot.Shots = []domain.Point{domain.Point(move)}
+		if IsWinner {
+			return []domain.Event{shot, gameOverEvent(game)}, nil
+		}
+		return []domain.Event{shot}, nil
+	})
+	if err != nil {
 		gs.sendError(err.Error(),playerId)
 		return
 	}
-	defer gs.repo.DeleteLock(ctx,roomID)
 
 	// delete timer for current player
-	gs.repo.RedisClient.Del(ctx,"turn:"+roomID)
+	gs.repo.ClearTimeOut(ctx,"turn:"+roomID)
+	
+	gs.BroadcastMoveResult(result, roomID, move, game, playerId, sunk)
+	if IsWinner {
+		gs.sendGameOver(ctx, game)
+		return
+	}
 
-	game, err := gs.repo.GetGame(ctx, roomID)
-	if err != nil {
-		gs.sendError("Game Not Found",playerId)
+	//start timer for next player
+	gs.StartTimer(roomID, game.Rules.TurnDuration())
+	gs.scheduleBotTurn(game)
+}
+
+func (gs *GameService) HandleSalvo(ctx context.Context, playerId string, roomID string, payload json.RawMessage) {
+	var salvo models.SalvoPayload
+	if err := json.Unmarshal(payload, &salvo); err != nil {
+		gs.sendError("Invalid salvo data",playerId)
 		return
 	}
-	// handle shot 
-	result, err := game.HandleShot(playerId, domain.Point(move))
+
+	var result domain.SalvoResult
+	var IsWinner bool
+
+	game, err := gs.updateGame(ctx, roomID, func(game *domain.Game) ([]domain.Event, error) {
+		// all shots are resolved together, a rejected salvo keeps the current turn and timer
+		var err error
+		result, err = game.HandleSalvo(playerId, salvo.Shots)
+		if err != nil {
+			return nil, err
+		}
+
+		IsWinner = game.CheckWinner(playerId)
+		game.AddEndAt(game.Rules.TurnDuration())
+
+		fired := domain.NewEvent(domain.EventSalvo,playerId)
+		fired.Shots = salvo.Shots
+		if IsWinner {
+			return []domain.Event{fired, gameOverEvent(game)}, nil
+		}
+		return []domain.Event{fired}, nil
+	})
 	if err != nil {
 		gs.sendError(err.Error(),playerId)
 		return
 	}
-	// check for winner
-	IsWinner := game.CheckWinner(playerId)
-	
-	// Switch ActivePlayer
-	game.SwitchActivePlayer(playerId)
-	game.AddEndAt(40*time.Second)
 
+	gs.repo.ClearTimeOut(ctx,"turn:"+roomID)
+
+	gs.SendToRoom(roomID, models.TypeSalvo, models.SalvoResultPayload{
+		Shots:     result.Shots,
+		Sunk:      result.Sunk,
+		NextTurn:  game.ActivePlayer,
+		NextShots: game.SalvoSize(game.ActivePlayer),
+		By:        playerId,
+		EndAt:     game.EndAt,
+	})
 
-	if err := gs.repo.SaveGame(ctx, game); err != nil {
-		gs.sendError( "Failed to save the game",playerId)
+	if IsWinner {
+		gs.sendGameOver(ctx, game)
 		return
 	}
-	
-	gs.BroadcastMoveResult(result, roomID, move, game, playerId, IsWinner)
 
-	//start timer for next player
-	gs.StartTimer(roomID)
+	gs.StartTimer(roomID, game.Rules.TurnDuration())
+	gs.scheduleBotTurn(game)
 }
 
 func (gs *GameService) HandlePlace(ctx context.Context, playerId string, RoomID string, payload json.RawMessage) {
@@ -88,60 +180,87 @@ func (gs *GameService) HandlePlace(ctx context.Context, playerId string, RoomID
 		return
 	}
 	
-	if err := gs.repo.LockGame(ctx, RoomID); err != nil {
-		gs.sendError(err.Error(),playerId)
-		return
-	}
-	
-	defer gs.repo.DeleteLock(ctx, RoomID)
+	var started bool
 
-	game, err := gs.repo.GetGame(ctx, RoomID)
-	
+	game, err := gs.updateGame(ctx, RoomID, func(game *domain.Game) ([]domain.Event, error) {
+		// add Ship for a player, the game rejects a second fleet so a bad layout can be resent
+		if err := game.AddShip(playerId, ships.Ships); err != nil {
+			return nil, err
+		}
+
+		started = game.AllShipsPlaced()
+		if started {
+			game.Status = domain.StatusActive
+			game.AddEndAt(game.Rules.TurnDuration())
+		}
+
+		placed := domain.NewEvent(domain.EventPlace,playerId)
+		placed.Ships = ships.Ships
+		return []domain.Event{placed}, nil
+	})
 	if err != nil {
 		gs.sendError(err.Error(),playerId)
 		return
 	}
-	
-	size,errShip := gs.repo.AddPlayerShip(ctx,RoomID,playerId)
-	
-	if errShip != nil {
-		gs.sendError(errShip.Error(),playerId)
-		return
-	}
-	// add Ship for a player
-	
-	game.AddShip(playerId, ships.Ships)
-	
-	if size == 2 {
-		key := "place:"+game.ID
-		gs.repo.RedisClient.Del(ctx,key)
-		game.Status = domain.StatusActive
-		game.AddEndAt(40*time.Second)
-	}
 
-	if err := gs.repo.SaveGame(ctx, game); err != nil {
-		gs.sendError("Failed to save Game",playerId)
-		return
+	if placed, err := gs.repo.AddPlayerShip(ctx,RoomID,playerId); err != nil || placed.Status != repository.Admitted {
+		log.Printf("Failed to mark ships of %s as placed in %s, status : %v, err : %v",playerId,RoomID,placed.Status,err)
 	}
 
 	// Place Payload
-	if size == 2 {
+	if started {
+		gs.repo.ClearTimeOut(ctx,"place:"+game.ID)
 		gs.SendGameHistoryToRoom( ctx,RoomID);
-		gs.StartTimer(game.ID)
+		gs.StartTimer(game.ID, game.Rules.TurnDuration())
+		gs.scheduleBotTurn(game)
 	}
 }
 
 func (gs *GameService) HandleJoin(ctx context.Context, playerId string,roomID string) error {
+
+	if bot.IsBot(playerId) {
+		return errors.New("Invalid player id")
+	}
+
+	// tournament and matchmaking rooms only seat the players they were made for, the bot takes its seat without joining
+	if room, err := gs.repo.GetRoom(ctx,roomID); err == nil && len(room.Seats) > 0 && !slices.Contains(room.Seats,playerId) {
+		return ErrSeatReserved
+	}
 	
-	if gs.repo.FindPlayer(ctx,roomID,playerId) {
+	joined,err := gs.repo.AddPlayerToGame(ctx,roomID,playerId)
+	if err != nil{
+		return err
+	}
+	switch joined.Status {
+	case repository.AlreadyPresent:
 		// send game updated state / previous state
 		gs.SendGameHistory(ctx,playerId,roomID)
+		gs.SendChatHistory(ctx,playerId,roomID)
 		return nil
+	case repository.Full:
+		return ErrGameFull
+	}
+	number := joined.Count
+	gs.recordEvent(ctx,roomID,domain.NewEvent(domain.EventJoin,playerId))
+	gs.repo.TouchRoom(ctx,roomID)
+
+	_, isBotRoom := gs.repo.GetRoomBot(ctx,roomID)
+	if isBotRoom && number == 1 {
+		seated,err := gs.seatBot(ctx,roomID)
+		if err != nil {
+			return err
+		}
+		if seated.Status == repository.Full {
+			return ErrGameFull
+		}
+		number = seated.Count
+		gs.recordEvent(ctx,roomID,domain.NewEvent(domain.EventJoin,bot.PlayerID(roomID)))
 	}
 
-	number,err := gs.repo.AddPlayerToGame(ctx,roomID,playerId)
-	if err != nil{
-		return err
+	if number == 2 {
+		gs.lobby.RoomChanged(ctx,roomID,models.LobbyFilled)
+	} else {
+		gs.lobby.RoomChanged(ctx,roomID,models.LobbyUpdated)
 	}
 	
 	if number == 2 {
@@ -149,13 +268,33 @@ func (gs *GameService) HandleJoin(ctx context.Context, playerId string,roomID st
 		if len(players) == 0 || err!= nil {
 			return errors.New("Game doesnot Exist")
 		}
-		game := domain.NewGame(players[0],players[1],roomID)
-		game.AddEndAt(60*time.Second)
-		if err := gs.repo.SaveGame(ctx,game);err != nil {
+		rules,err := gs.repo.GetRules(ctx,roomID)
+		if err != nil {
+			return err
+		}
+		game := domain.NewGame(players[0],players[1],roomID,rules)
+		game.AddEndAt(rules.PlacementDuration())
+		created := domain.NewEvent(domain.EventCreate,"")
+		created.Players = game.Players[:]
+		created.Rules = &rules
+
+		events := []domain.Event{created}
+		if isBotRoom {
+			botPlaced,err := gs.placeBotShips(game)
+			if err != nil {
+				return err
+			}
+			events = append(events,botPlaced)
+		}
+		if err := gs.repo.SaveGame(ctx,game,events...);err != nil {
 			return errors.New("Failed to save Game")
 		}
+		if isBotRoom {
+			gs.markBotPlaced(ctx,game.ID)
+		}
+		gs.setRoomStatus(ctx,roomID,domain.RoomPlaying)
 		key := "place:"+game.ID
-		gs.repo.SetTimeOut(ctx,key,60*time.Second)
+		gs.repo.SetTimeOut(ctx,key,rules.PlacementDuration())
 		
 		gs.SendGameHistoryToRoom(ctx,roomID);
 	}
@@ -165,21 +304,23 @@ func (gs *GameService) HandleJoin(ctx context.Context, playerId string,roomID st
 
 func (gs *GameService) HandleTurnTimeOut(gameID string)  {
 	
-	//get game
-	game, err := gs.repo.GetGame(context.Background(),gameID);
-	if err!=nil {
-		log.Println(err)
-		return
-	}
-
-	//swtich activePlayer and add new timer
-	game.SwitchActivePlayer(game.ActivePlayer)
-	game.AddEndAt(40*time.Second)
-
-	errGame := gs.repo.SaveGame(context.Background(),game);
+	game, err := gs.updateGame(context.Background(), gameID, func(game *domain.Game) ([]domain.Event, error) {
+		// a timer left over from a finished round, or from a turn that was played
+		// while the timer fired, must not touch the game
+		if game.Status != domain.StatusActive || time.Now().UnixMilli() < game.EndAt {
+			return nil, errNoChange
+		}
 
-	if errGame != nil{
-		log.Println(errGame)
+		//swtich activePlayer and add new timer
+		timedOut := game.ActivePlayer
+		game.SwitchActivePlayer(game.ActivePlayer)
+		game.AddEndAt(game.Rules.TurnDuration())
+		return []domain.Event{domain.NewEvent(domain.EventTimeOut,timedOut)}, nil
+	})
+	if err != nil {
+		if !errors.Is(err, errNoChange) {
+			log.Println(err)
+		}
 		return
 	}
 
@@ -189,34 +330,58 @@ func (gs *GameService) HandleTurnTimeOut(gameID string)  {
 	}
 
 	gs.SendToRoom(gameID,models.TypeTimeOut,timeOutPayload)
+	gs.StartTimer(gameID, game.Rules.TurnDuration())
+	gs.scheduleBotTurn(game)
 }
 
 func (gs *GameService) HandleDisconnect(gameID string,playerID string)  {
-	//get game
-	game,err := gs.repo.GetGame(context.Background(),gameID)
-	
-	if err != nil {
-		log.Println(err)
-		return
-	}
-
-	//winner
-	game.Winner = game.GetOpponent(playerID)
-
-	if err := gs.repo.SaveGame(context.Background(),game); err!= nil{
-		log.Println(err)
-	}
-	
-	//send game over
-	gs.SendToRoom(gameID,models.TypeGameOver,&models.GameOverPayload{Winner: game.Winner})
+	gs.endGame(gameID,domain.NewEvent(domain.EventDisconnect,playerID))
 }
 
 func (gs *GameService) HandlePlaceTimeOut(gameID string)  {
-	gs.SendToRoom(gameID,models.TypeGameOver,&models.GameOverPayload{Winner: ""})
+	gs.endGame(gameID,domain.NewEvent(domain.EventGameOver,""))
 }
 
 func (gs *GameService) HandleGameLimit(gameID string)  {
-	gs.SendToRoom(gameID,models.TypeGameOver,&models.GameOverPayload{Winner: ""})
+	gs.endGame(gameID,domain.NewEvent(domain.EventGameOver,""))
+}
+
+// HandleRoomExpired cleans up after a room nobody played in for domain.RoomTTL
+func (gs *GameService) HandleRoomExpired(roomID string)  {
+	gs.lobby.RoomClosed(context.Background(),roomID)
+	if err := gs.repo.DeleteRoom(context.Background(),roomID); err != nil {
+		log.Printf("Failed to delete room %s, err : %v",roomID,err)
+	}
+}
+
+// endGame finishes a game that ended without a winning shot and tells the room,
+// games that are already over are left alone.
+func (gs *GameService) endGame(gameID string, e domain.Event)  {
+	ctx := context.Background()
+
+	game,err := gs.updateGame(ctx, gameID, func(game *domain.Game) ([]domain.Event, error) {
+		if game.Status == domain.StatusOver {
+			return nil, errNoChange
+		}
+		// somebody who was turned away from a full or reserved room leaving does not end the game
+		if e.Type == domain.EventDisconnect && !slices.Contains(game.Players[:], e.Player) {
+			return nil, errNoChange
+		}
+
+		if err := game.Apply(e); err != nil {
+			return nil, err
+		}
+		return []domain.Event{e}, nil
+	})
+	if err != nil {
+		if !errors.Is(err, errNoChange) {
+			log.Println(err)
+		}
+		return
+	}
+	
+	//send game over
+	gs.sendGameOver(ctx,game)
 }
 
 // Helpers
@@ -238,17 +403,29 @@ func (gs *GameService) SendGameHistory(ctx context.Context,playerId string,roomI
 		Id: roomID,
 		YourBoard: yourBoard,
 		OpponentBoard: opponentBoard,
+		OpponentSunk: game.SunkShips(game.GetOpponent(playerId)),
 		ActivePlayer: game.ActivePlayer,
 		Winner: game.Winner,
 		Status: game.Status,
 		EndAt: game.EndAt,
+		Rules: game.Rules,
+		ShotsPerTurn: 1,
+		Round: game.Round,
+	}
+
+	if series,err := gs.repo.GetSeries(ctx,roomID); err == nil {
+		gameState.Series = series
+	}
+
+	if game.Rules.Mode == domain.ModeSalvo {
+		gameState.ShotsPerTurn = game.SalvoSize(game.ActivePlayer)
 	}
 
 	// Send it to Solo send
 	gs.SendToSolo(ctx,playerId,models.TypeGameState,gameState)
 }
 
-func (gs *GameService) BroadcastMoveResult(result domain.CellState, roomId string, move models.MovePayload, game *domain.Game, playerId string, IsWinner bool) {
+func (gs *GameService) BroadcastMoveResult(result domain.CellState, roomId string, move models.MovePayload, game *domain.Game, playerId string, sunk *domain.PlacedShip) {
 	resultPayload := models.HitPayload{
 		X:        move.X,
 		Y:        move.Y,
@@ -260,8 +437,38 @@ func (gs *GameService) BroadcastMoveResult(result domain.CellState, roomId strin
 
 	gs.SendToRoom(roomId, models.TypeMove, resultPayload)
 
-	if IsWinner {
-		gs.SendToRoom(roomId, models.TypeGameOver, models.GameOverPayload{Winner: playerId})
+	if sunk != nil {
+		gs.SendToRoom(roomId, models.TypeShipSunk, models.ShipSunkPayload{
+			Owner: game.GetOpponent(playerId),
+			By:    playerId,
+			Name:  sunk.Name,
+			Cells: sunk.Cells,
+		})
+	}
+}
+
+// sendGameOver rates the players, tells the room that the game is over and moves the series
+// and the tournament of the room forward
+func (gs *GameService) sendGameOver(ctx context.Context, game *domain.Game) {
+	gs.SendToRoom(game.ID, models.TypeGameOver, models.GameOverPayload{
+		Winner:  game.Winner,
+		Ratings: gs.updateRatings(ctx, game),
+	})
+	gs.updateSeries(ctx, game)
+	gs.tournaments.HandleGameOver(ctx, game)
+	gs.setRoomStatus(ctx, game.ID, domain.RoomFinished)
+}
+
+func (gs *GameService) setRoomStatus(ctx context.Context, roomID string, status domain.RoomStatus) {
+	if err := gs.repo.SetRoomStatus(ctx, roomID, status); err != nil {
+		log.Printf("Failed to set status of room %s to %s, err : %v", roomID, status, err)
+		return
+	}
+
+	if status == domain.RoomPlaying {
+		gs.lobby.RoomChanged(ctx, roomID, models.LobbyStarted)
+	} else {
+		gs.lobby.RoomChanged(ctx, roomID, models.LobbyUpdated)
 	}
 }
 
@@ -287,7 +494,28 @@ func (gs *GameService) SendToRoom(roomId string, msgType models.MessageType, pay
 	
 }
 
+// SendToSpectators sends the message to the spectators of the room only
+func (gs *GameService) SendToSpectators(roomId string, msgType models.MessageType, payload interface{}) {
+	response := models.MessageWs{
+		Type:    msgType,
+		Payload: toRawMessage(payload),
+	}
+
+	msg, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("failed to marshal response :%v", response)
+		return
+	}
+
+	gs.hub.SpectatorMessage(roomId,msg)
+}
+
 func (gs *GameService) SendToSolo(ctx context.Context,playerID string,msgType models.MessageType,payload interface{})  {
+	// the bot has no connection to send to
+	if bot.IsBot(playerID) {
+		return
+	}
+
 	// get serverId first
 	ServerID := gs.repo.GetPlayerServer(ctx,playerID)
 
@@ -314,6 +542,48 @@ func (gs *GameService) SendToSolo(ctx context.Context,playerID string,msgType mo
 	
 }
 
+// recordEvent appends a state transition to the event log of the game
+func (gs *GameService) recordEvent(ctx context.Context, gameID string, e domain.Event) {
+	if err := gs.repo.AppendEvent(ctx, gameID, e); err != nil {
+		log.Printf("Failed to record %s event for game %s, err : %v", e.Type, gameID, err)
+	}
+}
+
+func gameOverEvent(game *domain.Game) domain.Event {
+	over := domain.NewEvent(domain.EventGameOver, "")
+	over.Winner = game.Winner
+	return over
+}
+
+// updateGame loads the game, lets change apply a player action to it and saves it with the
+// events change returns, but only if nobody saved the game in between. After losing such a race
+// change runs again on the fresh game, so it must not have side effects outside of the game.
+func (gs *GameService) updateGame(ctx context.Context, gameID string, change func(*domain.Game) ([]domain.Event, error)) (*domain.Game, error) {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		game, err := gs.repo.GetGame(ctx, gameID)
+		if err != nil {
+			return nil, ErrGameNotFound
+		}
+
+		events, err := change(game)
+		if err != nil {
+			return nil, err
+		}
+
+		err = gs.repo.SaveGame(ctx, game, events...)
+		if errors.Is(err, domain.ErrVersionConflict) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to save game %s, err : %v", gameID, err)
+			return nil, ErrSaveGame
+		}
+		gs.repo.TouchRoom(ctx, gameID)
+		return game, nil
+	}
+	return nil, domain.ErrVersionConflict
+}
+
 func toRawMessage(v any) json.RawMessage {
 	b, err := json.Marshal(v)
 	if err != nil {
@@ -331,11 +601,11 @@ func (gs *GameService) SendGameHistoryToRoom(ctx context.Context,roomId string)
 	for _, p := range players {
 		gs.SendGameHistory(ctx,p,roomId);
 	}
+	gs.SendSpectatorState(ctx,roomId)
 }
 
-func (gs *GameService) StartTimer(gameID string)  {
+func (gs *GameService) StartTimer(gameID string, limit time.Duration)  {
 	key := "turn:"+gameID
-	limit := 40 * time.Second
 
 	gs.repo.SetTimeOut(context.Background(),key,limit)
 }
\ No newline at end of file
@@ -0,0 +1,672 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/bot"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/memory"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+type fakeHub struct {
+	mu     sync.Mutex
+	room   []models.MessageType
+	solo   map[string][]models.MessageType
+	last   map[string]json.RawMessage
+	lobby  []models.LobbyUpdatePayload
+	errors []string
+	// spectator holds the payloads sent on the spectator channel, in order
+	spectator []json.RawMessage
+}
+
+func (h *fakeHub) record(payload []byte) models.MessageType {
+	var msg models.MessageWs
+	json.Unmarshal(payload, &msg)
+	if msg.Type == models.TypeError {
+		var e models.ErrorPayload
+		json.Unmarshal(msg.Payload, &e)
+		h.errors = append(h.errors, e.Message)
+	}
+	return msg.Type
+}
+
+func (h *fakeHub) BroadcastMessage(roomID string, payload []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.room = append(h.room, h.record(payload))
+}
+
+func (h *fakeHub) SoloMessage(channel string, payload []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.solo == nil {
+		h.solo = make(map[string][]models.MessageType)
+		h.last = make(map[string]json.RawMessage)
+	}
+	h.solo[channel] = append(h.solo[channel], h.record(payload))
+	var msg models.MessageWs
+	json.Unmarshal(payload, &msg)
+	h.last[channel] = msg.Payload
+}
+
+// lastToPlayer decodes the payload of the last message sent to the player into v
+func (h *fakeHub) lastToPlayer(t *testing.T, playerID string, v any) {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := json.Unmarshal(h.last["solo:server:"+playerID], v); err != nil {
+		t.Fatalf("last message to %s: %v", playerID, err)
+	}
+}
+
+func (h *fakeHub) SpectatorMessage(roomID string, payload []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var msg models.MessageWs
+	json.Unmarshal(payload, &msg)
+	h.spectator = append(h.spectator, msg.Payload)
+}
+
+// lastToSpectators decodes the payload of the last message sent to the spectators into v
+func (h *fakeHub) lastToSpectators(t *testing.T, v any) {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.spectator) == 0 {
+		t.Fatal("expected a message to the spectators")
+	}
+	if err := json.Unmarshal(h.spectator[len(h.spectator)-1], v); err != nil {
+		t.Fatalf("last message to the spectators: %v", err)
+	}
+}
+
+func (h *fakeHub) LobbyMessage(payload []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var msg models.MessageWs
+	json.Unmarshal(payload, &msg)
+	var update models.LobbyUpdatePayload
+	json.Unmarshal(msg.Payload, &update)
+	h.lobby = append(h.lobby, update)
+}
+
+// lobbyEvents returns the lobby events sent for the room, in order
+func (h *fakeHub) lobbyEvents(roomID string) []models.LobbyEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var events []models.LobbyEvent
+	for _, u := range h.lobby {
+		if u.RoomID == roomID {
+			events = append(events, u.Event)
+		}
+	}
+	return events
+}
+
+func (h *fakeHub) sentToRoom(t models.MessageType) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sent := range h.room {
+		if sent == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *fakeHub) count(t models.MessageType) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, sent := range h.room {
+		if sent == t {
+			n++
+		}
+	}
+	return n
+}
+
+func (h *fakeHub) sentToPlayer(playerID string, t models.MessageType) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sent := range h.solo["solo:server:"+playerID] {
+		if sent == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *fakeHub) countToPlayer(playerID string, t models.MessageType) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, sent := range h.solo["solo:server:"+playerID] {
+		if sent == t {
+			n++
+		}
+	}
+	return n
+}
+
+func rawJSON(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// startedGame seats A and B in a room of the memory repository and places both fleets
+func startedGame(t *testing.T) (*GameService, *memory.MemoryGameRepository, *fakeHub) {
+	t.Helper()
+	ctx := context.Background()
+	repo := memory.NewMemoryGameRepository()
+	hub := &fakeHub{}
+	gs := NewGameService(repo, hub)
+
+	fleet := models.PlacePayload{Ships: []domain.ShipPlacement{
+		{Origin: domain.Point{X: 0, Y: 0}, Length: 5, Orientation: domain.Horizontal},
+		{Origin: domain.Point{X: 1, Y: 0}, Length: 4, Orientation: domain.Horizontal},
+		{Origin: domain.Point{X: 2, Y: 0}, Length: 3, Orientation: domain.Horizontal},
+		{Origin: domain.Point{X: 3, Y: 0}, Length: 3, Orientation: domain.Horizontal},
+		{Origin: domain.Point{X: 5, Y: 5}, Length: 2, Orientation: domain.Vertical},
+	}}
+
+	for _, p := range []string{"A", "B"} {
+		repo.SetPresence(ctx, p, "server")
+		if err := gs.HandleJoin(ctx, p, "room"); err != nil {
+			t.Fatalf("join %s: %v", p, err)
+		}
+	}
+	for _, p := range []string{"A", "B"} {
+		gs.HandlePlace(ctx, p, "room", rawJSON(t, fleet))
+	}
+	return gs, repo, hub
+}
+
+func TestGameServiceMove(t *testing.T) {
+	ctx := context.Background()
+	gs, repo, hub := startedGame(t)
+
+	game, err := repo.GetGame(ctx, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Status != domain.StatusActive {
+		t.Fatalf("status: expected %v, got %v", domain.StatusActive, game.Status)
+	}
+	for _, p := range game.Players {
+		if !hub.sentToPlayer(p, models.TypeGameState) {
+			t.Errorf("expected GAME_STATE to be sent to %s", p)
+		}
+	}
+
+	shooter := game.ActivePlayer
+	waiting := game.GetOpponent(shooter)
+
+	gs.HandleMove(ctx, waiting, "room", rawJSON(t, models.MovePayload{X: 0, Y: 0}))
+	if !hub.sentToPlayer(waiting, models.TypeError) {
+		t.Error("expected ERROR for a move out of turn")
+	}
+
+	gs.HandleMove(ctx, shooter, "room", rawJSON(t, models.MovePayload{X: 9, Y: 9}))
+	if !hub.sentToRoom(models.TypeMove) {
+		t.Error("expected MOVE to be sent to the room")
+	}
+
+	game, _ = repo.GetGame(ctx, "room")
+	if game.ActivePlayer != waiting {
+		t.Errorf("active player: expected %s, got %s", waiting, game.ActivePlayer)
+	}
+	if game.Boards[waiting][9][9] != domain.Miss {
+		t.Errorf("cell: expected %v, got %v", domain.Miss, game.Boards[waiting][9][9])
+	}
+
+	events, _ := repo.GetEvents(ctx, "room")
+	if last := events[len(events)-1]; last.Type != domain.EventShot || last.Player != shooter {
+		t.Errorf("last event: expected SHOT by %s, got %s by %s", shooter, last.Type, last.Player)
+	}
+}
+
+func TestSpectatorState(t *testing.T) {
+	ctx := context.Background()
+	gs, repo, hub := startedGame(t)
+
+	// sink the smallest ship of one player, the rest of both fleets stays afloat
+	game, _ := repo.GetGame(ctx, "room")
+	shooter := game.ActivePlayer
+	target := game.GetOpponent(shooter)
+	var sunk domain.PlacedShip
+	for _, ship := range game.Ships[target] {
+		if sunk.Cells == nil || len(ship.Cells) < len(sunk.Cells) {
+			sunk = ship
+		}
+	}
+	for _, c := range sunk.Cells {
+		game.Boards[target][c.X][c.Y] = domain.Hit
+	}
+	if err := repo.SaveGame(ctx, game); err != nil {
+		t.Fatal(err)
+	}
+
+	repo.SetPresence(ctx, "spectator-1", "server")
+	if err := gs.HandleSpectate(ctx, "spectator-1", "room"); err != nil {
+		t.Fatal(err)
+	}
+	var joined models.SpectatorStateResponse
+	hub.lastToPlayer(t, "spectator-1", &joined)
+
+	gs.SendSpectatorState(ctx, "room")
+	var pushed models.SpectatorStateResponse
+	hub.lastToSpectators(t, &pushed)
+
+	for name, state := range map[string]models.SpectatorStateResponse{"joined": joined, "pushed": pushed} {
+		for _, p := range game.Players {
+			for x, row := range state.Boards[p] {
+				for y, cell := range row {
+					if cell == domain.Ship {
+						t.Fatalf("%s view: ship of %s shown at %d,%d", name, p, x, y)
+					}
+				}
+			}
+		}
+		for _, c := range sunk.Cells {
+			if cell := state.Boards[target][c.X][c.Y]; cell != domain.Hit {
+				t.Errorf("%s view: expected %v at %d,%d, got %v", name, domain.Hit, c.X, c.Y, cell)
+			}
+		}
+		if got := state.Sunk[target]; len(got) != 1 || got[0].Name != sunk.Name {
+			t.Errorf("%s view: expected only %s sunk, got %+v", name, sunk.Name, got)
+		}
+		if got := state.Sunk[shooter]; len(got) != 0 {
+			t.Errorf("%s view: expected no ship of %s, got %+v", name, shooter, got)
+		}
+	}
+}
+
+func TestGameServiceTurnTimeOut(t *testing.T) {
+	ctx := context.Background()
+	gs, repo, hub := startedGame(t)
+
+	game, _ := repo.GetGame(ctx, "room")
+	before := game.ActivePlayer
+
+	// a timer firing before the turn ends belongs to a turn that was already played
+	gs.HandleTurnTimeOut("room")
+	if hub.sentToRoom(models.TypeTimeOut) {
+		t.Fatal("expected no TIME_OUT while the turn is running")
+	}
+
+	game.EndAt = time.Now().Add(-time.Second).UnixMilli()
+	if err := repo.SaveGame(ctx, game); err != nil {
+		t.Fatal(err)
+	}
+
+	gs.HandleTurnTimeOut("room")
+	if !hub.sentToRoom(models.TypeTimeOut) {
+		t.Error("expected TIME_OUT to be sent to the room")
+	}
+
+	game, _ = repo.GetGame(ctx, "room")
+	if game.ActivePlayer == before {
+		t.Errorf("active player: expected turn to pass from %s", before)
+	}
+
+	// the snapshot is rebuilt from the event log once it is gone
+	repo.ClearTimeOut(ctx, "game:room")
+	rebuilt, err := repo.GetGame(ctx, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt.ActivePlayer != game.ActivePlayer || rebuilt.Status != game.Status {
+		t.Errorf("rebuilt game: expected %s %v, got %s %v", game.ActivePlayer, game.Status, rebuilt.ActivePlayer, rebuilt.Status)
+	}
+}
+
+func TestGameServiceConcurrentMoves(t *testing.T) {
+	ctx := context.Background()
+	gs, repo, hub := startedGame(t)
+
+	game, _ := repo.GetGame(ctx, "room")
+	shooter := game.ActivePlayer
+
+	// the same player fires at many cells at once, only one shot may land in the turn
+	var wg sync.WaitGroup
+	for y := 0; y < 10; y++ {
+		wg.Add(1)
+		go func(y int) {
+			defer wg.Done()
+			gs.HandleMove(ctx, shooter, "room", rawJSON(t, models.MovePayload{X: 9, Y: y}))
+		}(y)
+	}
+	wg.Wait()
+
+	if moves := hub.count(models.TypeMove); moves != 1 {
+		t.Errorf("moves: expected 1, got %d", moves)
+	}
+	for _, msg := range hub.errors {
+		if msg != domain.ErrNotYourTurn.Error() {
+			t.Errorf("unexpected error sent to player: %s", msg)
+		}
+	}
+
+	events, _ := repo.GetEvents(ctx, "room")
+	shots := 0
+	for _, e := range events {
+		if e.Type == domain.EventShot {
+			shots++
+		}
+	}
+	if shots != 1 {
+		t.Errorf("shot events: expected 1, got %d", shots)
+	}
+
+	game, _ = repo.GetGame(ctx, "room")
+	if game.ActivePlayer == shooter {
+		t.Errorf("active player: expected turn to pass from %s", shooter)
+	}
+}
+
+func TestRoomLifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryGameRepository()
+	hs := CreateHttpService(repo)
+	gs := NewGameService(repo, &fakeHub{})
+
+	if _, err := hs.RoomGenerator(models.CreateRoomRequest{Rules: domain.DefaultRuleSet(), Visibility: "HIDDEN"}); err != domain.ErrInvalidVisibility {
+		t.Errorf("visibility: expected %v, got %v", domain.ErrInvalidVisibility, err)
+	}
+
+	room, err := hs.RoomGenerator(models.CreateRoomRequest{Rules: domain.DefaultRuleSet(), Creator: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hs.RoomValidator(room.ID) || hs.RoomValidator("missing") {
+		t.Error("expected only the created room to be valid")
+	}
+
+	for _, p := range []string{"A", "B"} {
+		if err := gs.HandleJoin(ctx, p, room.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gs.HandleJoin(ctx, "C", room.ID); err != ErrGameFull {
+		t.Errorf("third player: expected %v, got %v", ErrGameFull, err)
+	}
+
+	info, err := hs.RoomInfo(ctx, room.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != domain.RoomPlaying || info.Visibility != domain.VisibilityPrivate || len(info.Players) != 2 {
+		t.Errorf("room info: got %+v", info)
+	}
+
+	gs.HandleRoomExpired(room.ID)
+	if _, err := hs.RoomInfo(ctx, room.ID); err != domain.ErrRoomNotFound {
+		t.Errorf("expired room: expected %v, got %v", domain.ErrRoomNotFound, err)
+	}
+	if players, _ := repo.GetPlayers(ctx, room.ID); len(players) != 0 {
+		t.Errorf("players of an expired room: expected none, got %v", players)
+	}
+}
+
+func TestChatHistoryOnReconnect(t *testing.T) {
+	ctx := context.Background()
+	gs, repo, hub := startedGame(t)
+
+	gs.HandleChat(ctx, "A", "room", rawJSON(t, models.ChatPayload{Message: "gl hf"}))
+	gs.HandleChat(ctx, "A", "room", rawJSON(t, models.ChatPayload{Message: "  "}))
+	if chats := hub.count(models.TypeChat); chats != 1 {
+		t.Errorf("chat messages: expected 1, got %d", chats)
+	}
+
+	if err := gs.HandleJoin(ctx, "B", "room"); err != nil {
+		t.Fatal(err)
+	}
+	if !hub.sentToPlayer("B", models.TypeChatHistory) {
+		t.Error("expected CHAT_HISTORY to be sent to the returning player")
+	}
+
+	hs := CreateHttpService(repo)
+	if _, err := hs.ChatHistory(ctx, "room", "B", "not-an-id"); err != domain.ErrInvalidChatID {
+		t.Errorf("before: expected %v, got %v", domain.ErrInvalidChatID, err)
+	}
+}
+
+func TestChatModeration(t *testing.T) {
+	ctx := context.Background()
+	gs, repo, hub := startedGame(t)
+	gs.SetChatFilter(domain.NewChatFilter([]string{"darn"}))
+
+	chat := func(player string, text string) {
+		gs.HandleChat(ctx, player, "room", rawJSON(t, models.ChatPayload{Message: text}))
+	}
+
+	chat("A", strings.Repeat("a", domain.MaxChatLength+1))
+	if !hub.sentToPlayer("A", models.TypeError) || hub.sentToRoom(models.TypeChat) {
+		t.Fatal("expected a too long message to be rejected")
+	}
+
+	chat("A", "darn it")
+	messages, _ := repo.GetChat(ctx, "room", "", domain.ChatPageSize)
+	if len(messages) != 1 || messages[0].Message != "**** it" {
+		t.Fatalf("chat: expected the masked message, got %+v", messages)
+	}
+
+	// B mutes A, only A still gets its own messages
+	gs.HandleChatMute(ctx, "B", "room", rawJSON(t, models.ChatMutePayload{Player: "B", Muted: true}))
+	if hub.sentToPlayer("B", models.TypeChatMute) {
+		t.Fatal("expected B to not be able to mute itself")
+	}
+	gs.HandleChatMute(ctx, "B", "room", rawJSON(t, models.ChatMutePayload{Player: "A", Muted: true}))
+	if !hub.sentToPlayer("B", models.TypeChatMute) {
+		t.Fatal("expected CHAT_MUTE to be sent back to B")
+	}
+	chat("A", "anyone there?")
+	if rooms := hub.count(models.TypeChat); rooms != 1 {
+		t.Errorf("room chat messages: expected 1, got %d", rooms)
+	}
+	if !hub.sentToPlayer("A", models.TypeChat) || hub.sentToPlayer("B", models.TypeChat) {
+		t.Error("expected the muted message to reach A only")
+	}
+
+	// two messages went out in this window already
+	for i := 0; i < domain.ChatRateLimit; i++ {
+		chat("A", "spam")
+	}
+	if !slices.Contains(hub.errors, domain.ErrChatRateLimited.Error()) {
+		t.Error("expected the messages above the rate limit to be rejected")
+	}
+	if messages, _ = repo.GetChat(ctx, "room", "", domain.ChatPageSize); len(messages) != domain.ChatRateLimit {
+		t.Errorf("stored messages: expected %d, got %d", domain.ChatRateLimit, len(messages))
+	}
+
+	gs.HandleChatReport(ctx, "A", "room", rawJSON(t, models.ChatReportPayload{MessageID: messages[0].ID}))
+	if hub.sentToPlayer("A", models.TypeChatReport) {
+		t.Error("expected A to not be able to report its own message")
+	}
+	gs.HandleChatReport(ctx, "B", "room", rawJSON(t, models.ChatReportPayload{MessageID: messages[0].ID, Reason: "rude"}))
+	if !hub.sentToPlayer("B", models.TypeChatReport) {
+		t.Error("expected CHAT_REPORT to be sent back to B")
+	}
+
+	// scrolling back is for the players of the room only and keeps the mutes
+	repo.SaveRoom(ctx, domain.NewRoom("room", "A", domain.DefaultRuleSet(), domain.VisibilityPrivate))
+	hs := CreateHttpService(repo)
+	if _, err := hs.ChatHistory(ctx, "room", "C", ""); err != ErrNotInRoom {
+		t.Errorf("history of an outsider: expected %v, got %v", ErrNotInRoom, err)
+	}
+	if page, err := hs.ChatHistory(ctx, "room", "A", ""); err != nil || len(page.Messages) != domain.ChatRateLimit {
+		t.Errorf("history of A: expected %d messages, got %+v %v", domain.ChatRateLimit, page, err)
+	}
+	if page, err := hs.ChatHistory(ctx, "room", "B", ""); err != nil || len(page.Messages) != 0 {
+		t.Errorf("history of B: expected the muted messages to be hidden, got %+v %v", page, err)
+	}
+}
+
+func TestMatchmaking(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryGameRepository()
+	hub := &fakeHub{}
+	gs := NewGameService(repo, hub)
+	hs := CreateHttpService(repo)
+
+	// two servers share the queue
+	cfg := DefaultMatchmakingConfig()
+	servers := []*Matchmaker{NewMatchmaker(repo, hs, gs, cfg), NewMatchmaker(repo, hs, gs, cfg)}
+
+	players := []string{"A", "B", "C", "D", "E", "F", "G", "H"}
+	for _, p := range players {
+		repo.SetPresence(ctx, p, "server")
+	}
+
+	queued, err := servers[0].Enqueue(ctx, "A", "")
+	if err != nil || !queued.Queued {
+		t.Fatalf("enqueue: got %+v %v", queued, err)
+	}
+	if hub.sentToPlayer("A", models.TypeMatchFound) {
+		t.Fatal("expected no match for a single player")
+	}
+
+	var wg sync.WaitGroup
+	for i, p := range players[1:] {
+		wg.Add(1)
+		go func(mm *Matchmaker, p string) {
+			defer wg.Done()
+			mm.Enqueue(ctx, p, "eu")
+		}(servers[i%2], p)
+	}
+	wg.Wait()
+	servers[0].Match(ctx)
+
+	for _, p := range players {
+		if n := hub.countToPlayer(p, models.TypeMatchFound); n != 1 {
+			t.Errorf("MATCH_FOUND to %s: expected 1, got %d", p, n)
+		}
+	}
+	if entries, _ := repo.QueueEntries(ctx); len(entries) != 0 {
+		t.Errorf("queue: expected it to be empty, got %+v", entries)
+	}
+
+	// the room of a match is kept for the matched pair
+	var found models.MatchFoundPayload
+	hub.lastToPlayer(t, "A", &found)
+	if err := gs.HandleJoin(ctx, "outsider", found.RoomID); err != ErrSeatReserved {
+		t.Errorf("outsider joining a matched room: expected %v, got %v", ErrSeatReserved, err)
+	}
+	for _, p := range []string{"A", found.Opponent} {
+		if err := gs.HandleJoin(ctx, p, found.RoomID); err != nil {
+			t.Errorf("join %s: %v", p, err)
+		}
+	}
+
+	// a player without a connection can not be told about a match
+	servers[0].Enqueue(ctx, "offline", "")
+	if entries, _ := repo.QueueEntries(ctx); len(entries) != 0 {
+		t.Errorf("queue: expected the offline player to be dropped, got %+v", entries)
+	}
+}
+
+func TestMatchmakingWindow(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryGameRepository()
+	hub := &fakeHub{}
+	gs := NewGameService(repo, hub)
+	mm := NewMatchmaker(repo, CreateHttpService(repo), gs, MatchmakingConfig{
+		Window:        100,
+		WindowGrowth:  10,
+		MaxWindow:     400,
+		RegionWait:    15 * time.Second,
+		MaxWait:       time.Minute,
+		BotDifficulty: "HUNT_TARGET",
+	})
+
+	queue := func(playerID string, rating int, region string, waited time.Duration) {
+		t.Helper()
+		repo.SetPresence(ctx, playerID, "server")
+		repo.Dequeue(ctx, playerID)
+		e := domain.QueueEntry{
+			PlayerID:   playerID,
+			EnqueuedAt: time.Now().Add(-waited).UnixMilli(),
+			Rating:     rating,
+			Region:     region,
+		}
+		if _, err := repo.Enqueue(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// too far apart until the window has grown
+	queue("A", 1200, "", 0)
+	queue("B", 1500, "", 0)
+	mm.Match(ctx)
+	if hub.sentToPlayer("A", models.TypeMatchFound) {
+		t.Fatal("expected no match outside the window")
+	}
+	queue("A", 1200, "", 25*time.Second)
+	mm.Match(ctx)
+	if !hub.sentToPlayer("A", models.TypeMatchFound) || !hub.sentToPlayer("B", models.TypeMatchFound) {
+		t.Fatal("expected a match once the window has grown")
+	}
+
+	// other regions only after the region wait
+	queue("C", 1200, "eu", 0)
+	queue("D", 1200, "us", 0)
+	mm.Match(ctx)
+	if hub.sentToPlayer("C", models.TypeMatchFound) {
+		t.Fatal("expected no match across regions right away")
+	}
+	queue("C", 1200, "eu", 20*time.Second)
+	queue("D", 1200, "us", 20*time.Second)
+	mm.Match(ctx)
+	if !hub.sentToPlayer("C", models.TypeMatchFound) {
+		t.Fatal("expected a match across regions after the region wait")
+	}
+
+	// the closest rating wins
+	queue("E", 1200, "", 2*time.Second)
+	queue("F", 1290, "", time.Second)
+	queue("G", 1210, "", 0)
+	mm.Match(ctx)
+	var found models.MatchFoundPayload
+	hub.lastToPlayer(t, "E", &found)
+	if found.Opponent != "G" {
+		t.Errorf("opponent of E: expected G, got %+v", found)
+	}
+
+	// F is alone, the bot takes over after the max wait
+	queue("F", 1290, "", time.Minute)
+	mm.Match(ctx)
+	hub.lastToPlayer(t, "F", &found)
+	if !found.Bot || !bot.IsBot(found.Opponent) {
+		t.Errorf("expected F to play the bot, got %+v", found)
+	}
+	if entries, _ := repo.QueueEntries(ctx); len(entries) != 0 {
+		t.Errorf("queue: expected it to be empty, got %+v", entries)
+	}
+
+	// without a bot the player is taken out of the queue
+	mm.cfg.BotDifficulty = ""
+	queue("H", 1200, "", time.Minute)
+	mm.Match(ctx)
+	var left models.QueuePayload
+	hub.lastToPlayer(t, "H", &left)
+	if left.Queued || left.Reason != reasonTimeout {
+		t.Errorf("expected H to time out, got %+v", left)
+	}
+
+	status, err := mm.QueueStatus(ctx)
+	if err != nil || len(status.Players) != 0 || status.Settings.MaxWaitMs != time.Minute.Milliseconds() {
+		t.Errorf("queue status: got %+v %v", status, err)
+	}
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// seasonCheckInterval is how often every server looks for the start of a new season
+const seasonCheckInterval = time.Minute
+
+const (
+	// SeasonCurrent names the running season, the global boards between seasons
+	SeasonCurrent = "current"
+	SeasonGlobal  = "global"
+)
+
+var ErrInvalidPage = errors.New("Offset must not be negative and limit must be positive")
+
+// Leaderboard ranks the players of ranked games globally and per season. Boards of past
+// seasons are never deleted so they stay queryable after the season ended.
+type Leaderboard struct {
+	repo    LeaderboardRepository
+	users   UserRepository
+	seasons []domain.Season
+}
+
+// NewLeaderboard takes the seasons ordered by start, as domain.ReadSeasons returns them
+func NewLeaderboard(repo LeaderboardRepository, users UserRepository, seasons []domain.Season) *Leaderboard {
+	return &Leaderboard{
+		repo:    repo,
+		users:   users,
+		seasons: seasons,
+	}
+}
+
+// Run starts the seasons on time until ctx is done
+func (l *Leaderboard) Run(ctx context.Context) {
+	l.startSeason(ctx, time.Now())
+
+	ticker := time.NewTicker(seasonCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.startSeason(ctx, now)
+		}
+	}
+}
+
+// startSeason soft resets every rating and the global rating board once when a season
+// after the first one runs, the repository makes sure only one server of the cluster does it.
+// The board goes first since it is only reset once per season, a failed start is released
+// and tried again on the next check.
+func (l *Leaderboard) startSeason(ctx context.Context, now time.Time) {
+	season, ok := domain.SeasonAt(l.seasons, now)
+	if !ok || season.ID == l.seasons[0].ID {
+		return
+	}
+
+	claimed, err := l.repo.ClaimSeasonStart(ctx, season.ID)
+	if err != nil || !claimed {
+		return
+	}
+	err = l.repo.SoftResetRatingBoard(ctx, domain.GlobalScope, season.ID)
+	if err == nil {
+		err = l.users.SoftResetRatings(ctx)
+	}
+	if err != nil {
+		log.Printf("Failed to reset the ratings for season %s, err : %v", season.ID, err)
+		if err := l.repo.ReleaseSeasonStart(ctx, season.ID); err != nil {
+			log.Printf("Failed to release the start of season %s, err : %v", season.ID, err)
+		}
+		return
+	}
+	log.Printf("Season %s started, ratings were soft reset", season.ID)
+}
+
+// Record puts the result of a ranked game on the global boards and the boards of the
+// running season. A nil Leaderboard records nothing.
+func (l *Leaderboard) Record(ctx context.Context, playerID string, rating int, won bool) {
+	if l == nil {
+		return
+	}
+
+	scopes := []string{domain.GlobalScope}
+	if season, ok := domain.SeasonAt(l.seasons, time.Now()); ok {
+		scopes = append(scopes, season.Scope())
+	}
+	for _, scope := range scopes {
+		if err := l.repo.RecordResult(ctx, scope, playerID, rating, won); err != nil {
+			log.Printf("Failed to record %s on the %s leaderboards, err : %v", playerID, scope, err)
+		}
+	}
+}
+
+// Seasons returns every configured season, past ones included
+func (l *Leaderboard) Seasons() []domain.Season {
+	return l.seasons
+}
+
+// season finds the season a request names, no season stands for the global boards
+func (l *Leaderboard) season(name string, now time.Time) (*domain.Season, error) {
+	switch name {
+	case "", SeasonCurrent:
+		if season, ok := domain.SeasonAt(l.seasons, now); ok {
+			return &season, nil
+		}
+		return nil, nil
+	case SeasonGlobal:
+		return nil, nil
+	}
+
+	for _, s := range l.seasons {
+		if s.ID == name {
+			return &s, nil
+		}
+	}
+	return nil, domain.ErrSeasonNotFound
+}
+
+// Page returns a page of a board with the names of the players and,
+// when caller is set, the place of the caller
+func (l *Leaderboard) Page(ctx context.Context, board string, seasonName string, offset int64, limit int64, caller string) (models.LeaderboardResponse, error) {
+	b, err := domain.ParseBoard(board)
+	if err != nil {
+		return models.LeaderboardResponse{}, err
+	}
+	if offset < 0 || limit < 0 {
+		return models.LeaderboardResponse{}, ErrInvalidPage
+	}
+	if limit == 0 {
+		limit = domain.LeaderboardPageSize
+	}
+	limit = min(limit, domain.MaxLeaderboardPageSize)
+
+	season, err := l.season(seasonName, time.Now())
+	if err != nil {
+		return models.LeaderboardResponse{}, err
+	}
+	scope := domain.GlobalScope
+	if season != nil {
+		scope = season.Scope()
+	}
+
+	entries, total, err := l.repo.GetLeaderboard(ctx, scope, b, offset, limit)
+	if err != nil {
+		return models.LeaderboardResponse{}, err
+	}
+	for i := range entries {
+		entries[i].Name = l.name(ctx, entries[i].PlayerID)
+	}
+
+	resp := models.LeaderboardResponse{
+		Board:   b,
+		Season:  season,
+		Total:   total,
+		Entries: entries,
+	}
+
+	if caller != "" {
+		me, err := l.repo.GetLeaderboardRank(ctx, scope, b, caller)
+		if err != nil && !errors.Is(err, domain.ErrNotRanked) {
+			return models.LeaderboardResponse{}, err
+		}
+		if err == nil {
+			me.Name = l.name(ctx, caller)
+			resp.Me = &me
+		}
+	}
+	return resp, nil
+}
+
+// name is the display name of a ranked player, empty when the user is gone
+func (l *Leaderboard) name(ctx context.Context, playerID string) string {
+	user, err := l.users.GetUser(ctx, playerID)
+	if err != nil {
+		return ""
+	}
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Username
+}
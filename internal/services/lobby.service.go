@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+const (
+	LobbyPageSize    = 20
+	MaxLobbyPageSize = 100
+)
+
+// LobbyService lists the public rooms that can still be joined and tells the lobby connections when they change
+type LobbyService struct {
+	repo  LobbyRepository
+	rooms *HttpService
+	hub   HubInterface
+}
+
+func NewLobbyService(repo LobbyRepository, rooms *HttpService, hub HubInterface) *LobbyService {
+	return &LobbyService{
+		repo:  repo,
+		rooms: rooms,
+		hub:   hub,
+	}
+}
+
+// SetLobby lets joins and status changes of public rooms reach the lobby
+func (gs *GameService) SetLobby(ls *LobbyService) {
+	gs.lobby = ls
+}
+
+// List returns a page of the public rooms, newest first. Rooms that expired
+// without being cleaned up are taken off the lobby on the way.
+func (ls *LobbyService) List(ctx context.Context, offset int64, limit int64) (models.LobbyResponse, error) {
+	if offset < 0 || limit < 0 {
+		return models.LobbyResponse{}, ErrInvalidPage
+	}
+	if limit == 0 {
+		limit = LobbyPageSize
+	}
+	limit = min(limit, MaxLobbyPageSize)
+
+	ids, total, err := ls.repo.PublicRooms(ctx, offset, limit)
+	if err != nil {
+		return models.LobbyResponse{}, err
+	}
+
+	rooms := make([]models.LobbyRoom, 0, len(ids))
+	for _, id := range ids {
+		room, err := ls.lobbyRoom(ctx, id)
+		if errors.Is(err, domain.ErrRoomNotFound) || (err == nil && !joinable(room)) {
+			ls.repo.RemovePublicRoom(ctx, id)
+			total--
+			continue
+		}
+		if err != nil {
+			return models.LobbyResponse{}, err
+		}
+		rooms = append(rooms, *room)
+	}
+	return models.LobbyResponse{Rooms: rooms, Total: total}, nil
+}
+
+func (ls *LobbyService) lobbyRoom(ctx context.Context, roomID string) (*models.LobbyRoom, error) {
+	room, err := ls.rooms.repo.GetRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	players, err := ls.rooms.repo.GetPlayers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LobbyRoom{
+		ID:        room.ID,
+		Host:      room.Creator,
+		Rules:     room.Rules,
+		Status:    room.Status,
+		Players:   len(players),
+		BestOf:    room.BestOf,
+		CreatedAt: room.CreatedAt,
+	}, nil
+}
+
+// joinable tells if a listed room still waits for players, the lobby only lists those
+func joinable(room *models.LobbyRoom) bool {
+	return room.Status == domain.RoomOpen && room.Players < 2
+}
+
+// RoomChanged pushes the current state of a public room to the lobby, private rooms
+// are never announced. A room that filled or started leaves the lobby with this event
+// and is not announced again. A nil LobbyService does nothing.
+func (ls *LobbyService) RoomChanged(ctx context.Context, roomID string, event models.LobbyEvent) {
+	if ls == nil {
+		return
+	}
+	room, err := ls.rooms.repo.GetRoom(ctx, roomID)
+	if err != nil || room.Visibility != domain.VisibilityPublic {
+		return
+	}
+
+	listed, err := ls.lobbyRoom(ctx, roomID)
+	if err != nil {
+		log.Printf("Failed to read room %s for the lobby, err : %v", roomID, err)
+		return
+	}
+	if !joinable(listed) {
+		removed, err := ls.repo.RemovePublicRoom(ctx, roomID)
+		if err != nil || !removed {
+			return
+		}
+	}
+	ls.send(models.LobbyUpdatePayload{Event: event, RoomID: roomID, Room: listed})
+}
+
+// RoomClosed takes a room off the lobby and tells the lobby when it was listed
+func (ls *LobbyService) RoomClosed(ctx context.Context, roomID string) {
+	if ls == nil {
+		return
+	}
+	listed, err := ls.repo.RemovePublicRoom(ctx, roomID)
+	if err != nil || !listed {
+		return
+	}
+	ls.send(models.LobbyUpdatePayload{Event: models.LobbyClosed, RoomID: roomID})
+}
+
+func (ls *LobbyService) send(update models.LobbyUpdatePayload) {
+	msg, err := json.Marshal(models.MessageWs{
+		Type:    models.TypeLobbyUpdate,
+		Payload: toRawMessage(update),
+	})
+	if err != nil {
+		log.Printf("failed to marshal lobby update :%v", update)
+		return
+	}
+	ls.hub.LobbyMessage(msg)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/memory"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+func TestLobby(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryGameRepository()
+	hub := &fakeHub{}
+	hs := CreateHttpService(repo)
+	gs := NewGameService(repo, hub)
+	ls := NewLobbyService(repo, hs, hub)
+	gs.SetLobby(ls)
+
+	public, err := hs.RoomGenerator(models.CreateRoomRequest{Rules: domain.DefaultRuleSet(), Creator: "A", Visibility: "PUBLIC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls.RoomChanged(ctx, public.ID, models.LobbyOpened)
+	private, err := hs.RoomGenerator(models.CreateRoomRequest{Rules: domain.DefaultRuleSet(), Creator: "C"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls.RoomChanged(ctx, private.ID, models.LobbyOpened)
+
+	if err := gs.HandleJoin(ctx, "A", public.ID); err != nil {
+		t.Fatal(err)
+	}
+	page, err := ls.List(ctx, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || len(page.Rooms) != 1 {
+		t.Fatalf("lobby: expected only the public room, got %+v", page)
+	}
+	if room := page.Rooms[0]; room.ID != public.ID || room.Host != "A" || room.Players != 1 || room.Status != domain.RoomOpen {
+		t.Errorf("listed room: got %+v", room)
+	}
+
+	if err := gs.HandleJoin(ctx, "B", public.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := gs.HandleJoin(ctx, "C", private.ID); err != nil {
+		t.Fatal(err)
+	}
+	// a full room can not be joined anymore, it leaves the lobby when it fills
+	if page, _ := ls.List(ctx, 0, 0); page.Total != 0 || len(page.Rooms) != 0 {
+		t.Errorf("lobby after the room filled: expected it empty, got %+v", page)
+	}
+	gs.HandleRoomExpired(public.ID)
+
+	want := []models.LobbyEvent{models.LobbyOpened, models.LobbyUpdated, models.LobbyFilled}
+	if got := hub.lobbyEvents(public.ID); !slices.Equal(got, want) {
+		t.Errorf("public room events: expected %v, got %v", want, got)
+	}
+	if got := hub.lobbyEvents(private.ID); len(got) != 0 {
+		t.Errorf("private room events: expected none, got %v", got)
+	}
+
+	// an open room that expires is closed in the lobby
+	idle, err := hs.RoomGenerator(models.CreateRoomRequest{Rules: domain.DefaultRuleSet(), Creator: "D", Visibility: "PUBLIC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls.RoomChanged(ctx, idle.ID, models.LobbyOpened)
+	gs.HandleRoomExpired(idle.ID)
+	want = []models.LobbyEvent{models.LobbyOpened, models.LobbyClosed}
+	if got := hub.lobbyEvents(idle.ID); !slices.Equal(got, want) {
+		t.Errorf("idle room events: expected %v, got %v", want, got)
+	}
+
+	if _, err := ls.List(ctx, -1, 0); err != ErrInvalidPage {
+		t.Errorf("negative offset: expected %v, got %v", ErrInvalidPage, err)
+	}
+	if page, _ := ls.List(ctx, 0, 0); page.Total != 0 || len(page.Rooms) != 0 {
+		t.Errorf("lobby after the room closed: expected it empty, got %+v", page)
+	}
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/bot"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// matchInterval is how often every server looks for matches in the queue
+const matchInterval = time.Second
+
+// reasonTimeout tells a player it was taken out of the queue after waiting MaxWait
+const reasonTimeout = "timeout"
+
+var ErrInvalidMatchmaking = errors.New("Invalid matchmaking settings")
+
+// MatchmakingConfig tunes who plays whom. A player accepts opponents within Window rating
+// points, the window grows by WindowGrowth points every second of waiting up to MaxWindow.
+// Players of different regions are only paired once both waited RegionWait. After MaxWait
+// the player plays the bot of BotDifficulty, without one it is taken out of the queue.
+type MatchmakingConfig struct {
+	Window        int
+	WindowGrowth  int
+	MaxWindow     int
+	RegionWait    time.Duration
+	MaxWait       time.Duration
+	BotDifficulty string
+}
+
+func DefaultMatchmakingConfig() MatchmakingConfig {
+	return MatchmakingConfig{
+		Window:        100,
+		WindowGrowth:  10,
+		MaxWindow:     400,
+		RegionWait:    15 * time.Second,
+		MaxWait:       time.Minute,
+		BotDifficulty: string(bot.HuntTarget),
+	}
+}
+
+func (c MatchmakingConfig) Validate() error {
+	if c.Window < 0 || c.WindowGrowth < 0 || c.MaxWindow < c.Window {
+		return ErrInvalidMatchmaking
+	}
+	if c.RegionWait < 0 || c.MaxWait <= 0 {
+		return ErrInvalidMatchmaking
+	}
+	if c.BotDifficulty != "" {
+		if _, err := bot.ParseDifficulty(c.BotDifficulty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// window is how far from its own rating the player accepts opponents at now
+func (c MatchmakingConfig) window(e domain.QueueEntry, now time.Time) int {
+	grown := c.Window + int(e.Waited(now)/time.Second)*c.WindowGrowth
+	return min(max(grown, c.Window), c.MaxWindow)
+}
+
+// fits tells if two waiting players may be paired at now, the wider of both windows counts
+// so a player who waited long is not held back by a newcomer
+func (c MatchmakingConfig) fits(a domain.QueueEntry, b domain.QueueEntry, now time.Time) bool {
+	if !a.SameRegion(b) && (a.Waited(now) < c.RegionWait || b.Waited(now) < c.RegionWait) {
+		return false
+	}
+	return ratingGap(a, b) <= max(c.window(a, now), c.window(b, now))
+}
+
+func ratingGap(a domain.QueueEntry, b domain.QueueEntry) int {
+	if a.Rating > b.Rating {
+		return a.Rating - b.Rating
+	}
+	return b.Rating - a.Rating
+}
+
+// Matchmaker pairs the players of the shared queue into rooms. Every server runs one,
+// the repository makes sure a player is only matched once.
+type Matchmaker struct {
+	repo  QueueRepository
+	rooms *HttpService
+	gs    *GameService
+	cfg   MatchmakingConfig
+}
+
+func NewMatchmaker(repo QueueRepository, rooms *HttpService, gs *GameService, cfg MatchmakingConfig) *Matchmaker {
+	return &Matchmaker{
+		repo:  repo,
+		rooms: rooms,
+		gs:    gs,
+		cfg:   cfg,
+	}
+}
+
+// Run looks for matches until ctx is done
+func (m *Matchmaker) Run(ctx context.Context) {
+	ticker := time.NewTicker(matchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Match(ctx)
+		}
+	}
+}
+
+// Enqueue puts the player into the queue with its current rating and looks for a match
+// right away, queueing again keeps the place in the queue
+func (m *Matchmaker) Enqueue(ctx context.Context, playerID string, region string) (models.QueuePayload, error) {
+	entry, err := domain.NewQueueEntry(playerID, m.gs.playerRating(ctx, playerID), region)
+	if err != nil {
+		return models.QueuePayload{}, err
+	}
+	added, err := m.repo.Enqueue(ctx, entry)
+	if err != nil {
+		return models.QueuePayload{}, err
+	}
+	if !added {
+		entry = m.entry(ctx, playerID, entry)
+	}
+
+	m.Match(ctx)
+	return models.QueuePayload{
+		Queued:     true,
+		EnqueuedAt: entry.EnqueuedAt,
+		Rating:     entry.Rating,
+		Region:     entry.Region,
+	}, nil
+}
+
+// entry returns the queue entry of a player who was already waiting
+func (m *Matchmaker) entry(ctx context.Context, playerID string, fallback domain.QueueEntry) domain.QueueEntry {
+	entries, err := m.repo.QueueEntries(ctx)
+	if err != nil {
+		return fallback
+	}
+	for _, e := range entries {
+		if e.PlayerID == playerID {
+			return e
+		}
+	}
+	return fallback
+}
+
+// Leave takes the player out of the queue
+func (m *Matchmaker) Leave(ctx context.Context, playerID string) error {
+	_, err := m.repo.Dequeue(ctx, playerID)
+	return err
+}
+
+// HandleQueue answers a QUEUE message of a lobby connection, the payload may name a region
+func (m *Matchmaker) HandleQueue(ctx context.Context, playerID string, payload json.RawMessage) {
+	var req models.QueueRequest
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &req); err != nil {
+			m.gs.sendError("Invalid queue request", playerID)
+			return
+		}
+	}
+
+	queued, err := m.Enqueue(ctx, playerID, req.Region)
+	if errors.Is(err, domain.ErrInvalidRegion) {
+		m.gs.sendError(err.Error(), playerID)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to queue %s, err : %v", playerID, err)
+		m.gs.sendError("Failed to join the queue", playerID)
+		return
+	}
+	m.gs.SendToSolo(ctx, playerID, models.TypeQueue, queued)
+}
+
+// HandleQueueLeave answers a QUEUE_LEAVE message of a lobby connection
+func (m *Matchmaker) HandleQueueLeave(ctx context.Context, playerID string) {
+	if err := m.Leave(ctx, playerID); err != nil {
+		log.Printf("Failed to take %s out of the queue, err : %v", playerID, err)
+		m.gs.sendError("Failed to leave the queue", playerID)
+		return
+	}
+	m.gs.SendToSolo(ctx, playerID, models.TypeQueueLeave, models.QueuePayload{Queued: false})
+}
+
+// QueueStatus shows the waiting players and the window each of them accepts right now
+func (m *Matchmaker) QueueStatus(ctx context.Context) (models.QueueStatusResponse, error) {
+	entries, err := m.repo.QueueEntries(ctx)
+	if err != nil {
+		return models.QueueStatusResponse{}, err
+	}
+	sortByWait(entries)
+
+	now := time.Now()
+	players := make([]models.QueuedPlayer, 0, len(entries))
+	for _, e := range entries {
+		players = append(players, models.QueuedPlayer{
+			QueueEntry: e,
+			WaitedMs:   e.Waited(now).Milliseconds(),
+			Window:     m.cfg.window(e, now),
+		})
+	}
+
+	return models.QueueStatusResponse{
+		Settings: models.MatchmakingSettings{
+			Window:        m.cfg.Window,
+			WindowGrowth:  m.cfg.WindowGrowth,
+			MaxWindow:     m.cfg.MaxWindow,
+			RegionWaitMs:  m.cfg.RegionWait.Milliseconds(),
+			MaxWaitMs:     m.cfg.MaxWait.Milliseconds(),
+			BotDifficulty: m.cfg.BotDifficulty,
+		},
+		Players: players,
+	}, nil
+}
+
+func sortByWait(entries []domain.QueueEntry) {
+	slices.SortFunc(entries, func(a, b domain.QueueEntry) int {
+		return cmp.Compare(a.EnqueuedAt, b.EnqueuedAt)
+	})
+}
+
+// Match pairs every waiting player, longest waiting first, with the closest rated player
+// that fits its window. Players who waited MaxWait without a fit get the bot. Players
+// without a connection can not be told about their match, they are dropped from the queue.
+func (m *Matchmaker) Match(ctx context.Context) {
+	entries, err := m.repo.QueueEntries(ctx)
+	if err != nil {
+		log.Printf("Failed to read the matchmaking queue, err : %v", err)
+		return
+	}
+	sortByWait(entries)
+
+	var waiting []domain.QueueEntry
+	for _, e := range entries {
+		if m.gs.repo.GetPlayerServer(ctx, e.PlayerID) == "" {
+			m.repo.Dequeue(ctx, e.PlayerID)
+			continue
+		}
+		waiting = append(waiting, e)
+	}
+
+	now := time.Now()
+	done := make(map[string]bool)
+	for i, a := range waiting {
+		if done[a.PlayerID] {
+			continue
+		}
+
+		best := -1
+		for j := i + 1; j < len(waiting); j++ {
+			b := waiting[j]
+			if done[b.PlayerID] || !m.cfg.fits(a, b, now) {
+				continue
+			}
+			if best == -1 || ratingGap(a, b) < ratingGap(a, waiting[best]) {
+				best = j
+			}
+		}
+
+		if best != -1 {
+			if m.startMatch(ctx, a, waiting[best]) {
+				done[a.PlayerID] = true
+				done[waiting[best].PlayerID] = true
+			}
+			continue
+		}
+
+		if a.Waited(now) >= m.cfg.MaxWait {
+			m.timeOut(ctx, a)
+			done[a.PlayerID] = true
+		}
+	}
+}
+
+// startMatch claims both players, creates their room and tells them where to play
+func (m *Matchmaker) startMatch(ctx context.Context, a domain.QueueEntry, b domain.QueueEntry) bool {
+	claimed, err := m.repo.ClaimMatch(ctx, a.PlayerID, b.PlayerID)
+	if err != nil || !claimed {
+		// another server matched one of them first or one left the queue
+		return false
+	}
+
+	// the room is saved with both seats reserved before anybody is told about it
+	room, err := m.rooms.RoomGenerator(models.CreateRoomRequest{
+		Rules: domain.DefaultRuleSet(),
+		Seats: []string{a.PlayerID, b.PlayerID},
+	})
+	if err != nil {
+		log.Printf("Failed to create a room for %s and %s, err : %v", a.PlayerID, b.PlayerID, err)
+		// back into the queue with the place they had
+		m.repo.Enqueue(ctx, a)
+		m.repo.Enqueue(ctx, b)
+		return false
+	}
+
+	m.gs.SendToSolo(ctx, a.PlayerID, models.TypeMatchFound, models.MatchFoundPayload{RoomID: room.ID, Opponent: b.PlayerID})
+	m.gs.SendToSolo(ctx, b.PlayerID, models.TypeMatchFound, models.MatchFoundPayload{RoomID: room.ID, Opponent: a.PlayerID})
+	return true
+}
+
+// timeOut ends the wait of a player nobody fit, with a room against the bot
+// when there is a fallback difficulty and out of the queue otherwise
+func (m *Matchmaker) timeOut(ctx context.Context, e domain.QueueEntry) {
+	claimed, err := m.repo.ClaimMatch(ctx, e.PlayerID)
+	if err != nil || !claimed {
+		return
+	}
+
+	if m.cfg.BotDifficulty == "" {
+		m.gs.SendToSolo(ctx, e.PlayerID, models.TypeQueueLeave, models.QueuePayload{Queued: false, Reason: reasonTimeout})
+		return
+	}
+
+	room, err := m.rooms.RoomGenerator(models.CreateRoomRequest{
+		Rules:      domain.DefaultRuleSet(),
+		Opponent:   models.OpponentBot,
+		Difficulty: m.cfg.BotDifficulty,
+		Seats:      []string{e.PlayerID},
+	})
+	if err != nil {
+		log.Printf("Failed to create a bot room for %s, err : %v", e.PlayerID, err)
+		m.repo.Enqueue(ctx, e)
+		return
+	}
+
+	m.gs.SendToSolo(ctx, e.PlayerID, models.TypeMatchFound, models.MatchFoundPayload{
+		RoomID:   room.ID,
+		Opponent: bot.PlayerID(room.ID),
+		Bot:      true,
+	})
+}
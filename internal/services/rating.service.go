@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/bot"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// SetUsers lets games between registered users move their ratings
+func (gs *GameService) SetUsers(users UserRepository) {
+	gs.users = users
+}
+
+// SetLeaderboard puts the results of ranked games on the leaderboards
+func (gs *GameService) SetLeaderboard(lb *Leaderboard) {
+	gs.leaderboard = lb
+}
+
+// updateRatings moves the ratings of both players once a ranked game is over. A game is
+// ranked when it has a winner and both players are registered users, so games of guests,
+// games against the bot and games that timed out without a winner never count.
+func (gs *GameService) updateRatings(ctx context.Context, game *domain.Game) map[string]models.RatingChange {
+	if gs.users == nil || game.Winner == "" {
+		return nil
+	}
+	loser := game.GetOpponent(game.Winner)
+	if bot.IsBot(game.Winner) || bot.IsBot(loser) {
+		return nil
+	}
+
+	winnerUser, err := gs.rankedUser(ctx, game.Winner)
+	if err != nil || winnerUser == nil {
+		return nil
+	}
+	loserUser, err := gs.rankedUser(ctx, loser)
+	if err != nil || loserUser == nil {
+		return nil
+	}
+
+	gain, loss := domain.EloDeltas(winnerUser.Rating, loserUser.Rating)
+	changes := make(map[string]models.RatingChange)
+	for _, result := range []struct {
+		player string
+		delta  int
+		won    bool
+	}{{game.Winner, gain, true}, {loser, loss, false}} {
+		rating, err := gs.users.AddRatingResult(ctx, result.player, result.delta, result.won)
+		if err != nil {
+			log.Printf("Failed to rate %s for game %s, err : %v", result.player, game.ID, err)
+			continue
+		}
+		changes[result.player] = models.RatingChange{Rating: rating.Points, Delta: result.delta}
+		gs.leaderboard.Record(ctx, result.player, rating.Points, result.won)
+	}
+	return changes
+}
+
+// rankedUser returns the user of a player, nil for guests
+func (gs *GameService) rankedUser(ctx context.Context, playerID string) (*domain.User, error) {
+	user, err := gs.users.GetUser(ctx, playerID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Failed to get user %s, err : %v", playerID, err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetRating returns the rating of a registered player, guests have none
+func (as *AuthService) GetRating(ctx context.Context, playerID string) (models.RatingResponse, error) {
+	user, err := as.users.GetUser(ctx, playerID)
+	if err != nil {
+		return models.RatingResponse{}, err
+	}
+	return models.RatingResponse{
+		PlayerID: user.ID,
+		Rating:   user.Rating,
+	}, nil
+}
+
+// playerRating is the rating the matchmaker pairs a player by, guests start at the default rating
+func (gs *GameService) playerRating(ctx context.Context, playerID string) int {
+	if gs.users == nil {
+		return domain.DefaultRating
+	}
+	user, err := gs.rankedUser(ctx, playerID)
+	if err != nil || user == nil {
+		return domain.DefaultRating
+	}
+	return user.Rating.Points
+}
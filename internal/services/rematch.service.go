@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+var (
+	ErrRematchNotAllowed = errors.New("Rematch is only possible once the game is over")
+	ErrNoRematchRequest  = errors.New("Opponent has not asked for a rematch")
+	ErrNotInRoom         = errors.New("Player is not part of this room")
+)
+
+// HandleRematchRequest asks the opponent for a rematch, the rematch starts right away
+// when both players asked for one or when the opponent is the bot.
+func (gs *GameService) HandleRematchRequest(ctx context.Context, playerId string, roomID string) {
+	game, err := gs.finishedRound(ctx, playerId, roomID)
+	if err != nil {
+		gs.sendError(err.Error(), playerId)
+		return
+	}
+
+	requests, err := gs.repo.AddRematchRequest(ctx, roomID, playerId)
+	if err != nil {
+		gs.sendError(err.Error(), playerId)
+		return
+	}
+
+	if _, isBotRoom := gs.repo.GetRoomBot(ctx, roomID); isBotRoom || requests >= 2 {
+		gs.startRematch(ctx, game, playerId)
+		return
+	}
+
+	gs.SendToRoom(roomID, models.TypeRematchRequest, models.RematchPayload{
+		By:    playerId,
+		Round: game.Round + 1,
+	})
+}
+
+// HandleRematchAccept starts the rematch the opponent asked for
+func (gs *GameService) HandleRematchAccept(ctx context.Context, playerId string, roomID string) {
+	game, err := gs.finishedRound(ctx, playerId, roomID)
+	if err != nil {
+		gs.sendError(err.Error(), playerId)
+		return
+	}
+
+	if !gs.repo.HasRequestedRematch(ctx, roomID, game.GetOpponent(playerId)) {
+		gs.sendError(ErrNoRematchRequest.Error(), playerId)
+		return
+	}
+
+	gs.startRematch(ctx, game, playerId)
+}
+
+func (gs *GameService) finishedRound(ctx context.Context, playerId string, roomID string) (*domain.Game, error) {
+	if !gs.repo.FindPlayer(ctx, roomID, playerId) {
+		return nil, ErrNotInRoom
+	}
+
+	game, err := gs.repo.GetGame(ctx, roomID)
+	if err != nil {
+		return nil, ErrGameNotFound
+	}
+
+	if game.Status != domain.StatusOver {
+		return nil, ErrRematchNotAllowed
+	}
+	return game, nil
+}
+
+// startRematch replaces the finished game with a fresh one in the same room and
+// restarts ship placement, the players stay connected. When both players start the
+// rematch at the same time only the first save wins.
+func (gs *GameService) startRematch(ctx context.Context, old *domain.Game, playerId string) {
+	rules, err := gs.repo.GetRules(ctx, old.ID)
+	if err != nil {
+		gs.sendError(err.Error(), playerId)
+		return
+	}
+
+	game := old.Rematch(rules)
+	game.AddEndAt(rules.PlacementDuration())
+
+	created := domain.NewEvent(domain.EventCreate, "")
+	created.Players = game.Players[:]
+	created.Rules = &rules
+
+	events := []domain.Event{created}
+	_, isBotRoom := gs.repo.GetRoomBot(ctx, game.ID)
+	if isBotRoom {
+		botPlaced, err := gs.placeBotShips(game)
+		if err != nil {
+			log.Printf("bot failed to place ships in %s, err : %v", game.ID, err)
+			return
+		}
+		events = append(events, botPlaced)
+	}
+
+	if err := gs.repo.SaveGame(ctx, game, events...); err != nil {
+		if !errors.Is(err, domain.ErrVersionConflict) {
+			gs.sendError(ErrSaveGame.Error(), playerId)
+		}
+		return
+	}
+
+	if err := gs.repo.ResetRound(ctx, game.ID); err != nil {
+		log.Printf("Failed to reset round bookkeeping of %s, err : %v", game.ID, err)
+	}
+	if isBotRoom {
+		gs.markBotPlaced(ctx, game.ID)
+	}
+	gs.repo.ClearTimeOut(ctx, "turn:"+game.ID)
+
+	gs.repo.SetTimeOut(ctx, "place:"+game.ID, rules.PlacementDuration())
+	gs.resetDecidedSeries(ctx, game.ID)
+	gs.setRoomStatus(ctx, game.ID, domain.RoomPlaying)
+	gs.repo.TouchRoom(ctx, game.ID)
+
+	gs.SendToRoom(game.ID, models.TypeRematchAccept, models.RematchPayload{
+		By:    playerId,
+		Round: game.Round,
+	})
+	gs.SendGameHistoryToRoom(ctx, game.ID)
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+var (
+	ErrReplayNotFound = errors.New("No replay for this game")
+	ErrGameInProgress = errors.New("Game is still in progress")
+)
+
+// finishedGame loads the event log of one round of a game and checks that the round is over,
+// replays of running games would give away ship positions. Round 0 is the latest round.
+func (gs *GameService) finishedGame(ctx context.Context, gameID string, round int) (*domain.Game, []domain.Event, error) {
+	all, err := gs.repo.GetEvents(ctx, gameID)
+	if err != nil || len(all) == 0 {
+		return nil, nil, ErrReplayNotFound
+	}
+
+	rounds := domain.SplitRounds(all)
+	if round == 0 {
+		round = len(rounds)
+	}
+	if round < 0 || round > len(rounds) {
+		return nil, nil, ErrReplayNotFound
+	}
+	events := rounds[round-1]
+
+	game, err := domain.Replay(gameID, events)
+	if err != nil {
+		return nil, nil, ErrReplayNotFound
+	}
+	game.Round = round
+
+	if game.Status != domain.StatusOver {
+		return nil, nil, ErrGameInProgress
+	}
+	return game, events, nil
+}
+
+// GameReplay returns the ordered event log of a finished round of a game
+func (gs *GameService) GameReplay(ctx context.Context, gameID string, round int) (models.ReplayResponse, error) {
+	game, events, err := gs.finishedGame(ctx, gameID, round)
+	if err != nil {
+		return models.ReplayResponse{}, err
+	}
+
+	return models.ReplayResponse{
+		GameID:  gameID,
+		Round:   game.Round,
+		Players: game.Players,
+		Winner:  game.Winner,
+		Rules:   game.Rules,
+		Events:  events,
+	}, nil
+}
+
+// ReplayFrames turns the event log of a finished game back into the messages the
+// players received, so clients can render a replay with the live game renderer.
+func (gs *GameService) ReplayFrames(ctx context.Context, gameID string, round int) ([]models.ReplayFrame, error) {
+	_, events, err := gs.finishedGame(ctx, gameID, round)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := []models.ReplayFrame{}
+	frame := func(at int64, t models.MessageType, payload any) {
+		frames = append(frames, models.ReplayFrame{
+			At:      at,
+			Message: models.MessageWs{Type: t, Payload: toRawMessage(payload)},
+		})
+	}
+
+	var game *domain.Game
+	for _, e := range events {
+		if e.Type == domain.EventCreate {
+			game, err = domain.Replay(gameID, []domain.Event{e})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if game == nil {
+			continue
+		}
+
+		opponentID := game.GetOpponent(e.Player)
+		sunkBefore := sunkFlags(game, opponentID)
+
+		if err := game.Apply(e); err != nil {
+			return nil, err
+		}
+
+		switch e.Type {
+		case domain.EventPlace:
+			// the game is over so the replay can show where the ships were
+			if game.AllShipsPlaced() {
+				state := spectatorState(game)
+				for _, p := range game.Players {
+					state.Boards[p] = game.Boards[p]
+				}
+				frame(e.At, models.TypeSpectatorState, state)
+			}
+
+		case domain.EventShot:
+			p := e.Shots[0]
+			frame(e.At, models.TypeMove, models.HitPayload{
+				X:        p.X,
+				Y:        p.Y,
+				Result:   game.Boards[opponentID][p.X][p.Y],
+				NextTurn: game.ActivePlayer,
+				By:       e.Player,
+				EndAt:    game.EndAt,
+			})
+			for _, s := range newlySunk(game, opponentID, sunkBefore) {
+				frame(e.At, models.TypeShipSunk, models.ShipSunkPayload{
+					Owner: opponentID,
+					By:    e.Player,
+					Name:  s.Name,
+					Cells: s.Cells,
+				})
+			}
+
+		case domain.EventSalvo:
+			shots := make([]domain.ShotResult, len(e.Shots))
+			for i, p := range e.Shots {
+				shots[i] = domain.ShotResult{X: p.X, Y: p.Y, Result: game.Boards[opponentID][p.X][p.Y]}
+			}
+			frame(e.At, models.TypeSalvo, models.SalvoResultPayload{
+				Shots:     shots,
+				Sunk:      newlySunk(game, opponentID, sunkBefore),
+				NextTurn:  game.ActivePlayer,
+				NextShots: game.SalvoSize(game.ActivePlayer),
+				By:        e.Player,
+				EndAt:     game.EndAt,
+			})
+
+		case domain.EventTimeOut:
+			frame(e.At, models.TypeTimeOut, models.TimeOutPayload{
+				NextTurn: game.ActivePlayer,
+				EndAt:    game.EndAt,
+			})
+
+		case domain.EventDisconnect, domain.EventGameOver:
+			frame(e.At, models.TypeGameOver, models.GameOverPayload{Winner: game.Winner})
+		}
+	}
+
+	return frames, nil
+}
+
+func sunkFlags(game *domain.Game, ownerID string) []bool {
+	ships := game.Ships[ownerID]
+	flags := make([]bool, len(ships))
+	for i, s := range ships {
+		if len(s.Cells) > 0 {
+			_, flags[i] = game.SunkShip(ownerID, s.Cells[0])
+		}
+	}
+	return flags
+}
+
+func newlySunk(game *domain.Game, ownerID string, before []bool) []domain.PlacedShip {
+	sunk := []domain.PlacedShip{}
+	for i, now := range sunkFlags(game, ownerID) {
+		if now && (i >= len(before) || !before[i]) {
+			sunk = append(sunk, game.Ships[ownerID][i])
+		}
+	}
+	return sunk
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// GameRepository is the storage of rooms and their games, the Redis repository backs a
+// cluster of servers and the memory repository a single server or tests.
+type GameRepository interface {
+	// SaveGame saves g together with events only if the stored game is still at g.Version,
+	// it returns domain.ErrVersionConflict otherwise and bumps g.Version on success.
+	SaveGame(ctx context.Context, g *domain.Game, events ...domain.Event) error
+	GetGame(ctx context.Context, id string) (*domain.Game, error)
+
+	// AddPlayerToGame and AddPlayerShip are atomic, a set never holds more than two players
+	AddPlayerToGame(ctx context.Context, gameID string, playerID string) (repository.AdmitResult, error)
+	GetPlayers(ctx context.Context, gameID string) ([]string, error)
+	FindPlayer(ctx context.Context, gameID string, playerID string) bool
+	AddPlayerShip(ctx context.Context, gameID string, playerID string) (repository.AdmitResult, error)
+
+	SetPresence(ctx context.Context, playerID string, serverID string) error
+	RemovePresence(ctx context.Context, playerID string) error
+	GetPlayerServer(ctx context.Context, playerID string) string
+
+	// SetTimeOut starts a timer that fires once key expires, ClearTimeOut stops it
+	SetTimeOut(ctx context.Context, key string, limit time.Duration)
+	ClearTimeOut(ctx context.Context, key string)
+
+	// SaveRoom (re)starts the expiry of the room at room.TTL(), TouchRoom pushes it back to
+	// domain.RoomTTL unless it is further off. GetRoom returns domain.ErrRoomNotFound once
+	// it expired. DeleteRoom clears what is left of it.
+	SaveRoom(ctx context.Context, room *domain.Room) error
+	GetRoom(ctx context.Context, roomID string) (*domain.Room, error)
+	SetRoomStatus(ctx context.Context, roomID string, status domain.RoomStatus) error
+	TouchRoom(ctx context.Context, roomID string)
+	DeleteRoom(ctx context.Context, roomID string) error
+	RoomExists(ctx context.Context, roomID string) bool
+	GetRules(ctx context.Context, roomID string) (domain.RuleSet, error)
+	GetRoomBot(ctx context.Context, roomID string) (string, bool)
+
+	ChatRepository
+
+	AppendEvent(ctx context.Context, gameID string, e domain.Event) error
+	GetEvents(ctx context.Context, gameID string) ([]domain.Event, error)
+
+	AddRematchRequest(ctx context.Context, gameID string, playerID string) (int64, error)
+	HasRequestedRematch(ctx context.Context, gameID string, playerID string) bool
+	ResetRound(ctx context.Context, gameID string) error
+	SaveSeries(ctx context.Context, roomID string, s *domain.Series) error
+	GetSeries(ctx context.Context, roomID string) (*domain.Series, error)
+}
+
+// ChatRepository keeps the chat of the rooms. SaveChat fills in the ID of msg, GetChat pages
+// back from the message before, oldest first. The chat and its mutes are kept for ttl, the TTL of the room.
+type ChatRepository interface {
+	SaveChat(ctx context.Context, roomID string, msg *domain.ChatMessage, ttl time.Duration) error
+	GetChat(ctx context.Context, roomID string, before string, limit int64) ([]domain.ChatMessage, error)
+	GetChatMessage(ctx context.Context, roomID string, id string) (*domain.ChatMessage, error)
+	// CountChat returns how many messages playerID sent in the current domain.ChatRateWindow, this one included
+	CountChat(ctx context.Context, roomID string, playerID string) (int64, error)
+	SetChatMute(ctx context.Context, roomID string, playerID string, target string, muted bool, ttl time.Duration) error
+	GetChatMutes(ctx context.Context, roomID string, playerID string) ([]string, error)
+	SaveChatReport(ctx context.Context, report *domain.ChatReport) error
+}
+
+// QueueRepository is the matchmaking queue, it is shared by every server. ClaimMatch takes
+// all players of a match out of it or none so two servers never match the same player.
+type QueueRepository interface {
+	Enqueue(ctx context.Context, entry domain.QueueEntry) (bool, error)
+	Dequeue(ctx context.Context, playerID string) (bool, error)
+	QueueEntries(ctx context.Context) ([]domain.QueueEntry, error)
+	ClaimMatch(ctx context.Context, playerIDs ...string) (bool, error)
+}
+
+// LeaderboardRepository keeps the boards of ranked games, globally and per season
+type LeaderboardRepository interface {
+	// RecordResult puts the rating of a ranked game on the boards of scope, counts the win
+	// and moves the streak atomically
+	RecordResult(ctx context.Context, scope string, playerID string, rating int, won bool) error
+	// GetLeaderboard returns a page of a board, best first, and how many players are on it
+	GetLeaderboard(ctx context.Context, scope string, board domain.Board, offset int64, limit int64) ([]domain.LeaderboardEntry, int64, error)
+	// GetLeaderboardRank returns domain.ErrNotRanked for players who are not on the board
+	GetLeaderboardRank(ctx context.Context, scope string, board domain.Board, playerID string) (domain.LeaderboardEntry, error)
+	// ClaimSeasonStart is true for exactly one caller per season until the claim is released
+	ClaimSeasonStart(ctx context.Context, seasonID string) (bool, error)
+	ReleaseSeasonStart(ctx context.Context, seasonID string) error
+	// SoftResetRatingBoard applies domain.Rating.SoftReset to the rating board of scope,
+	// only the first call for a season changes the board
+	SoftResetRatingBoard(ctx context.Context, scope string, seasonID string) error
+}
+
+// TournamentRepository keeps the brackets of the tournaments
+type TournamentRepository interface {
+	// SaveTournament returns domain.ErrTournamentConflict when the tournament was saved
+	// by someone else since it was loaded
+	SaveTournament(ctx context.Context, t *domain.Tournament) error
+	// GetTournament returns domain.ErrTournamentNotFound for unknown tournaments
+	GetTournament(ctx context.Context, id string) (*domain.Tournament, error)
+}
+
+// LobbyRepository indexes the public rooms for the lobby
+type LobbyRepository interface {
+	// PublicRooms returns a page of the ids of public rooms, newest first, and how many are listed
+	PublicRooms(ctx context.Context, offset int64, limit int64) ([]string, int64, error)
+	RemovePublicRoom(ctx context.Context, roomID string) (bool, error)
+}
+
+// Repository is all a server stores besides its users, every backend implements all of it
+type Repository interface {
+	GameRepository
+	QueueRepository
+	LeaderboardRepository
+	TournamentRepository
+	LobbyRepository
+}
+
+// UserRepository keeps registered users, MongoDB in production and a local bolt file in development
+type UserRepository interface {
+	// CreateUser returns domain.ErrUsernameTaken when the username is in use
+	CreateUser(ctx context.Context, u *domain.User) error
+	// GetUser and GetUserByUsername return domain.ErrUserNotFound for unknown users
+	GetUser(ctx context.Context, id string) (*domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
+	UpdateProfile(ctx context.Context, id string, displayName string, avatarURL string) error
+	// AddRatingResult counts a won or lost ranked game and moves the rating by delta atomically
+	AddRatingResult(ctx context.Context, userID string, delta int, won bool) (domain.Rating, error)
+	// SoftResetRatings applies domain.Rating.SoftReset to every user
+	SoftResetRatings(ctx context.Context) error
+}
@@ -2,31 +2,101 @@ package services
 
 import (
 	"context"
+	"errors"
 
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/bot"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
 	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
-	"github.com/redis/go-redis/v9"
 )
 
+var ErrInvalidOpponent = errors.New("Invalid opponent, use \"bot\" or leave it empty")
+
 type HttpService struct {
-	rdb *redis.Client
+	repo GameRepository
 }
 
-func CreateHttpService(r *redis.Client) *HttpService {
+func CreateHttpService(r GameRepository) *HttpService {
 	return &HttpService{
-		rdb: r,
+		repo: r,
 	}
 }
 
 func (hs HttpService) RoomValidator(roomId string) bool {
-	return hs.rdb.SIsMember(context.Background(),"rooms",roomId).Val()
+	return hs.repo.RoomExists(context.Background(),roomId)
 }
 
-func (hs HttpService) RoomGenerator() (string,error) {
+func (hs HttpService) RoomGenerator(req models.CreateRoomRequest) (*domain.Room,error) {
+	if err := req.Rules.Validate(); err != nil {
+		return nil,err
+	}
+
+	if req.BestOf == 0 {
+		req.BestOf = 1
+	}
+	if err := domain.ValidateBestOf(req.BestOf); err != nil {
+		return nil,err
+	}
+
+	visibility, err := domain.ParseVisibility(req.Visibility)
+	if err != nil {
+		return nil,err
+	}
+
+	var difficulty bot.Difficulty
+	switch req.Opponent {
+	case "":
+	case models.OpponentBot:
+		d, err := bot.ParseDifficulty(req.Difficulty)
+		if err != nil {
+			return nil,err
+		}
+		difficulty = d
+	default:
+		return nil,ErrInvalidOpponent
+	}
+
 	roomID, err := domain.GenerateRoomID(5)
 	if err != nil {
-		return "",err
+		return nil,err
+	}
+
+	room := domain.NewRoom(roomID,req.Creator,req.Rules,visibility)
+	room.Bot = string(difficulty)
+	room.BestOf = req.BestOf
+	room.Seats = req.Seats
+
+	if req.BestOf > 1 {
+		if err := hs.repo.SaveSeries(context.Background(),roomID,domain.NewSeries(req.BestOf)); err != nil {
+			return nil,err
+		}
 	}
 
-	hs.rdb.SAdd(context.Background(),"rooms",roomID)
-	return roomID,nil
-}
\ No newline at end of file
+	if err := hs.repo.SaveRoom(context.Background(),room); err != nil {
+		return nil,err
+	}
+	return room,nil
+}
+
+// RoomInfo lets a player look at a room before joining it
+func (hs HttpService) RoomInfo(ctx context.Context, roomID string) (models.RoomResponse,error) {
+	room, err := hs.repo.GetRoom(ctx,roomID)
+	if err != nil {
+		return models.RoomResponse{},err
+	}
+
+	players, err := hs.repo.GetPlayers(ctx,roomID)
+	if err != nil {
+		return models.RoomResponse{},err
+	}
+
+	series, err := hs.repo.GetSeries(ctx,roomID)
+	if err != nil {
+		return models.RoomResponse{},err
+	}
+
+	return models.RoomResponse{
+		Room: *room,
+		Players: players,
+		Series: series,
+	},nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// updateSeries counts a finished round towards the series of the room and broadcasts the score
+func (gs *GameService) updateSeries(ctx context.Context, game *domain.Game) {
+	series, err := gs.repo.GetSeries(ctx, game.ID)
+	if err != nil {
+		log.Printf("Failed to get series of room %s, err : %v", game.ID, err)
+		return
+	}
+	if series == nil || !series.Record(game.Round, game.Winner) {
+		return
+	}
+
+	if err := gs.repo.SaveSeries(ctx, game.ID, series); err != nil {
+		log.Printf("Failed to save series of room %s, err : %v", game.ID, err)
+		return
+	}
+
+	gs.SendToRoom(game.ID, models.TypeSeriesUpdate, series)
+}
+
+// resetDecidedSeries starts a new series when a rematch follows a decided one
+func (gs *GameService) resetDecidedSeries(ctx context.Context, roomID string) {
+	series, err := gs.repo.GetSeries(ctx, roomID)
+	if err != nil || series == nil || !series.Decided() {
+		return
+	}
+
+	series.Reset()
+	if err := gs.repo.SaveSeries(ctx, roomID, series); err != nil {
+		log.Printf("Failed to save series of room %s, err : %v", roomID, err)
+	}
+}
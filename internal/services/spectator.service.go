@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+// HandleSpectate sends the current spectator view to a spectator that just joined,
+// rooms without a game yet send nothing, the view follows once the game is created.
+func (gs *GameService) HandleSpectate(ctx context.Context, spectatorID string, roomID string) error {
+	game, err := gs.repo.GetGame(ctx, roomID)
+	if err != nil {
+		return nil
+	}
+
+	gs.SendToSolo(ctx, spectatorID, models.TypeSpectatorState, spectatorState(game))
+	return nil
+}
+
+// SpectatorDelay is how long spectator delivery is held back in the room
+func (gs *GameService) SpectatorDelay(ctx context.Context, roomID string) time.Duration {
+	rules, err := gs.repo.GetRules(ctx, roomID)
+	if err != nil {
+		log.Printf("Failed To get rules of room %s, err : %v", roomID, err)
+		return 0
+	}
+	return rules.SpectatorDelayDuration()
+}
+
+// SendSpectatorState pushes the spectator view to every spectator of the room
+func (gs *GameService) SendSpectatorState(ctx context.Context, roomID string) {
+	game, err := gs.repo.GetGame(ctx, roomID)
+	if err != nil {
+		log.Printf("Failed To get game %s, err : %v", roomID, err)
+		return
+	}
+
+	gs.SendToSpectators(roomID, models.TypeSpectatorState, spectatorState(game))
+}
+
+func spectatorState(game *domain.Game) models.SpectatorStateResponse {
+	state := models.SpectatorStateResponse{
+		Id:           game.ID,
+		Players:      game.Players,
+		Boards:       make(map[string][][]domain.CellState),
+		Sunk:         make(map[string][]domain.PlacedShip),
+		ActivePlayer: game.ActivePlayer,
+		Winner:       game.Winner,
+		Status:       game.Status,
+		EndAt:        game.EndAt,
+		Rules:        game.Rules,
+	}
+
+	for _, p := range game.Players {
+		state.Boards[p] = game.HiddenBoard(p)
+		state.Sunk[p] = game.SunkShips(p)
+	}
+	return state
+}
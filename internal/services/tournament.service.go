@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+var (
+	ErrNotOrganizer = errors.New("Only the organizer of the tournament can do this")
+	ErrSeatReserved = errors.New("Room is reserved for other players")
+)
+
+// TournamentService runs elimination tournaments. Every bracket match is played in a room
+// of its own through the usual join flow, the result of the game moves the bracket on.
+type TournamentService struct {
+	repo  TournamentRepository
+	rooms *HttpService
+	gs    *GameService
+}
+
+func NewTournamentService(repo TournamentRepository, rooms *HttpService, gs *GameService) *TournamentService {
+	return &TournamentService{
+		repo:  repo,
+		rooms: rooms,
+		gs:    gs,
+	}
+}
+
+// SetTournaments lets finished games move the brackets of their tournaments
+func (gs *GameService) SetTournaments(ts *TournamentService) {
+	gs.tournaments = ts
+}
+
+// Create opens a tournament for registration with the caller as organizer
+func (ts *TournamentService) Create(ctx context.Context, organizer string, req models.CreateTournamentRequest) (*domain.Tournament, error) {
+	format, err := domain.ParseTournamentFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Rules.Validate(); err != nil {
+		return nil, err
+	}
+	if req.BestOf == 0 {
+		req.BestOf = 1
+	}
+
+	id, err := domain.GenerateRoomID(5)
+	if err != nil {
+		return nil, err
+	}
+	t, err := domain.NewTournament(id, req.Name, organizer, format, req.MaxPlayers, req.Rules, req.BestOf)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.repo.SaveTournament(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (ts *TournamentService) Get(ctx context.Context, id string) (*domain.Tournament, error) {
+	return ts.repo.GetTournament(ctx, id)
+}
+
+// Register signs the caller up, the organizer may sign up other players too
+func (ts *TournamentService) Register(ctx context.Context, id string, caller string, playerID string) (*domain.Tournament, error) {
+	if playerID == "" {
+		playerID = caller
+	}
+	return ts.update(ctx, id, func(t *domain.Tournament) error {
+		if playerID != caller && caller != t.Organizer {
+			return ErrNotOrganizer
+		}
+		return t.Register(playerID)
+	})
+}
+
+// Start draws the bracket and opens the rooms of the first matches
+func (ts *TournamentService) Start(ctx context.Context, id string, caller string) (*domain.Tournament, error) {
+	return ts.update(ctx, id, func(t *domain.Tournament) error {
+		if caller != t.Organizer {
+			return ErrNotOrganizer
+		}
+		return t.Start()
+	})
+}
+
+// Report lets the organizer decide a match, for games that ended without a winner
+// or players who never showed up
+func (ts *TournamentService) Report(ctx context.Context, id string, caller string, matchID string, winner string) (*domain.Tournament, error) {
+	return ts.update(ctx, id, func(t *domain.Tournament) error {
+		if caller != t.Organizer {
+			return ErrNotOrganizer
+		}
+		return t.Report(matchID, winner)
+	})
+}
+
+// HandleGameOver moves the winner of a tournament room on. Series count once they are
+// decided and games without a winner are left to the organizer. A nil TournamentService
+// does nothing.
+func (ts *TournamentService) HandleGameOver(ctx context.Context, game *domain.Game) {
+	if ts == nil {
+		return
+	}
+	room, err := ts.rooms.repo.GetRoom(ctx, game.ID)
+	if err != nil || room.Tournament == "" {
+		return
+	}
+
+	winner := game.Winner
+	if room.BestOf > 1 {
+		series, err := ts.rooms.repo.GetSeries(ctx, game.ID)
+		if err != nil || series == nil || !series.Decided() {
+			return
+		}
+		winner = series.Winner
+	}
+	if winner == "" {
+		return
+	}
+
+	_, err = ts.update(ctx, room.Tournament, func(t *domain.Tournament) error {
+		m := t.MatchByRoom(game.ID)
+		if m == nil {
+			return domain.ErrMatchNotFound
+		}
+		return t.Report(m.ID, winner)
+	})
+	// a rematch after the decided match does not count again
+	if err != nil && !errors.Is(err, domain.ErrMatchNotPlayable) {
+		log.Printf("Failed to report room %s to tournament %s, err : %v", game.ID, room.Tournament, err)
+	}
+}
+
+// update loads the tournament, applies change and gives every match that became playable
+// its room, then saves it unless somebody saved the tournament in between. After losing
+// such a race change runs again on the fresh tournament. The rooms are only opened and the
+// players only told once the save went through.
+func (ts *TournamentService) update(ctx context.Context, id string, change func(*domain.Tournament) error) (*domain.Tournament, error) {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		t, err := ts.repo.GetTournament(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := change(t); err != nil {
+			return nil, err
+		}
+
+		opened := t.Playable()
+		for _, m := range opened {
+			m.RoomID = t.ID + "-" + m.ID
+		}
+
+		err = ts.repo.SaveTournament(ctx, t)
+		if errors.Is(err, domain.ErrTournamentConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range opened {
+			if err := ts.rooms.openMatchRoom(ctx, t, m); err != nil {
+				log.Printf("Failed to open room of match %s in tournament %s, err : %v", m.ID, t.ID, err)
+			}
+		}
+		ts.notify(ctx, t)
+		return t, nil
+	}
+	return nil, domain.ErrTournamentConflict
+}
+
+// notify pushes the bracket to the organizer and every player of the tournament
+func (ts *TournamentService) notify(ctx context.Context, t *domain.Tournament) {
+	ts.gs.SendToSolo(ctx, t.Organizer, models.TypeTournamentUpdate, t)
+	for _, p := range t.Players {
+		if p != t.Organizer {
+			ts.gs.SendToSolo(ctx, p, models.TypeTournamentUpdate, t)
+		}
+	}
+}
+
+// openMatchRoom creates the room a tournament match is played in, only its two players may join
+func (hs HttpService) openMatchRoom(ctx context.Context, t *domain.Tournament, m *domain.TournamentMatch) error {
+	room := domain.NewRoom(m.RoomID, t.Organizer, t.Rules, domain.VisibilityPrivate)
+	room.BestOf = t.BestOf
+	room.Tournament = t.ID
+	room.Seats = []string{m.Players[0], m.Players[1]}
+
+	if t.BestOf > 1 {
+		if err := hs.repo.SaveSeries(ctx, room.ID, domain.NewSeries(t.BestOf)); err != nil {
+			return err
+		}
+	}
+	return hs.repo.SaveRoom(ctx, room)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/repository/memory"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+)
+
+func TestTournament(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryGameRepository()
+	hub := &fakeHub{}
+	gs := NewGameService(repo, hub)
+	ts := NewTournamentService(repo, CreateHttpService(repo), gs)
+	gs.SetTournaments(ts)
+
+	for _, p := range []string{"O", "A", "B", "C", "D"} {
+		repo.SetPresence(ctx, p, "server")
+	}
+
+	tour, err := ts.Create(ctx, "O", models.CreateTournamentRequest{Name: "Cup", Rules: domain.DefaultRuleSet()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"A", "B", "C"} {
+		if _, err := ts.Register(ctx, tour.ID, p, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ts.Register(ctx, tour.ID, "A", "D"); !errors.Is(err, ErrNotOrganizer) {
+		t.Errorf("register someone else: expected %v, got %v", ErrNotOrganizer, err)
+	}
+	if _, err := ts.Register(ctx, tour.ID, "O", "D"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Start(ctx, tour.ID, "A"); !errors.Is(err, ErrNotOrganizer) {
+		t.Errorf("start by a player: expected %v, got %v", ErrNotOrganizer, err)
+	}
+
+	tour, err = ts.Start(ctx, tour.ID, "O")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hub.sentToPlayer("D", models.TypeTournamentUpdate) {
+		t.Error("expected TOURNAMENT_UPDATE to be sent to D")
+	}
+
+	// A meets D and B meets C, each in a room only they may join
+	first, second := tour.Match("W1-1"), tour.Match("W1-2")
+	if first.Players != [2]string{"A", "D"} || first.RoomID == "" || second.RoomID == "" {
+		t.Fatalf("first round: got %+v and %+v", first, second)
+	}
+	if err := gs.HandleJoin(ctx, "C", first.RoomID); !errors.Is(err, ErrSeatReserved) {
+		t.Errorf("join someone else's match: expected %v, got %v", ErrSeatReserved, err)
+	}
+	for _, p := range first.Players {
+		if err := gs.HandleJoin(ctx, p, first.RoomID); err != nil {
+			t.Fatalf("join %s: %v", p, err)
+		}
+	}
+
+	// the turned away player leaving must not end the match
+	gs.HandleDisconnect(first.RoomID, "C")
+	if game, _ := repo.GetGame(ctx, first.RoomID); game.Status == domain.StatusOver {
+		t.Fatal("expected the match to go on after an outsider left")
+	}
+
+	gs.HandleDisconnect(first.RoomID, "D")
+	tour, _ = ts.Get(ctx, tour.ID)
+	if final := tour.Match("W2-1"); final.Players[0] != "A" || final.RoomID != "" {
+		t.Errorf("final after W1-1: got %+v", final)
+	}
+
+	// B and C never finish their game, the organizer decides
+	if _, err := ts.Report(ctx, tour.ID, "B", "W1-2", "B"); !errors.Is(err, ErrNotOrganizer) {
+		t.Errorf("report by a player: expected %v, got %v", ErrNotOrganizer, err)
+	}
+	tour, err = ts.Report(ctx, tour.ID, "O", "W1-2", "B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	final := tour.Match("W2-1")
+	if final.Players != [2]string{"A", "B"} || final.RoomID == "" {
+		t.Fatalf("final: got %+v", final)
+	}
+
+	for _, p := range final.Players {
+		if err := gs.HandleJoin(ctx, p, final.RoomID); err != nil {
+			t.Fatalf("join final %s: %v", p, err)
+		}
+	}
+	gs.HandleDisconnect(final.RoomID, "A")
+	tour, _ = ts.Get(ctx, tour.ID)
+	if tour.Status != domain.TournamentFinished || tour.Winner != "B" {
+		t.Errorf("expected B to win the tournament, got %s %q", tour.Status, tour.Winner)
+	}
+}
+
+// a best-of series only moves the bracket once it is decided
+func TestTournamentSeries(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryGameRepository()
+	hub := &fakeHub{}
+	gs := NewGameService(repo, hub)
+	ts := NewTournamentService(repo, CreateHttpService(repo), gs)
+	gs.SetTournaments(ts)
+
+	for _, p := range []string{"O", "A", "B"} {
+		repo.SetPresence(ctx, p, "server")
+	}
+	tour, err := ts.Create(ctx, "O", models.CreateTournamentRequest{Name: "Cup", Rules: domain.DefaultRuleSet(), BestOf: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"A", "B"} {
+		ts.Register(ctx, tour.ID, "O", p)
+	}
+	tour, err = ts.Start(ctx, tour.ID, "O")
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := tour.Match("W1-1")
+	for _, p := range match.Players {
+		if err := gs.HandleJoin(ctx, p, match.RoomID); err != nil {
+			t.Fatalf("join %s: %v", p, err)
+		}
+	}
+
+	gs.HandleDisconnect(match.RoomID, "B")
+	if tour, _ = ts.Get(ctx, tour.ID); tour.Status != domain.TournamentRunning || tour.Match("W1-1").Done {
+		t.Fatalf("after the first round: expected the match to go on, got %+v", tour.Match("W1-1"))
+	}
+
+	gs.HandleRematchRequest(ctx, "A", match.RoomID)
+	gs.HandleRematchAccept(ctx, "B", match.RoomID)
+	if game, _ := repo.GetGame(ctx, match.RoomID); game.Round != 2 {
+		t.Fatalf("expected the second round to start, got round %d", game.Round)
+	}
+	gs.HandleDisconnect(match.RoomID, "B")
+
+	tour, _ = ts.Get(ctx, tour.ID)
+	if tour.Status != domain.TournamentFinished || tour.Winner != "A" {
+		t.Errorf("expected A to win the series and the tournament, got %s %q", tour.Status, tour.Winner)
+	}
+}
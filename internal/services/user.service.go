@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Harish-Naruto/Space-Striker-Server/internal/models"
+	"github.com/Harish-Naruto/Space-Striker-Server/pkg/domain"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrGuestProfile = errors.New("Guests have no profile, register to get one")
+
+// dummyHash is compared against when a username does not exist,
+// so a failed login takes as long for unknown users as for wrong passwords
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("space-striker"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Register creates a user and signs it in
+func (as *AuthService) Register(ctx context.Context, req models.RegisterRequest) (models.SessionResponse, error) {
+	user, err := domain.NewUser("user-"+uuid.NewString(), req.Username, req.DisplayName, req.AvatarURL)
+	if err != nil {
+		return models.SessionResponse{}, err
+	}
+	if err := domain.ValidatePassword(req.Password); err != nil {
+		return models.SessionResponse{}, err
+	}
+
+	user.PasswordHash, err = bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.SessionResponse{}, err
+	}
+	if err := as.users.CreateUser(ctx, user); err != nil {
+		return models.SessionResponse{}, err
+	}
+	return as.userSession(user)
+}
+
+// Login signs a user in, unknown users and wrong passwords give the same error
+func (as *AuthService) Login(ctx context.Context, username string, password string) (models.SessionResponse, error) {
+	user, err := as.users.GetUserByUsername(ctx, domain.NormalizeUsername(username))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return models.SessionResponse{}, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.SessionResponse{}, err
+	}
+
+	if bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)) != nil {
+		return models.SessionResponse{}, domain.ErrInvalidCredentials
+	}
+	return as.userSession(user)
+}
+
+func (as *AuthService) userSession(user *domain.User) (models.SessionResponse, error) {
+	session, err := as.issue(user.ID, user.DisplayName, false)
+	if err != nil {
+		return models.SessionResponse{}, err
+	}
+	session.User = user
+	return session, nil
+}
+
+// GetProfile returns the public profile of a user
+func (as *AuthService) GetProfile(ctx context.Context, userID string) (*domain.User, error) {
+	return as.users.GetUser(ctx, userID)
+}
+
+// UpdateProfile changes the display name and avatar of the signed in user
+func (as *AuthService) UpdateProfile(ctx context.Context, claims *SessionClaims, req models.ProfileRequest) (*domain.User, error) {
+	if claims.Guest {
+		return nil, ErrGuestProfile
+	}
+
+	user, err := as.users.GetUser(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName, avatarURL := user.DisplayName, user.AvatarURL
+	if req.DisplayName != nil {
+		displayName = *req.DisplayName
+	}
+	if req.AvatarURL != nil {
+		avatarURL = *req.AvatarURL
+	}
+	if err := user.SetProfile(displayName, avatarURL); err != nil {
+		return nil, err
+	}
+
+	if err := as.users.UpdateProfile(ctx, user.ID, user.DisplayName, user.AvatarURL); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
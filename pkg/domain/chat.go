@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// ChatHistoryLimit is how many messages of a room are kept, older ones are dropped
+	ChatHistoryLimit = 200
+	// ChatPageSize is how many messages are sent in one page of history
+	ChatPageSize = 50
+
+	// MaxChatLength is the longest chat message in characters, reports use it for their reason too
+	MaxChatLength = 200
+	// a player may send ChatRateLimit messages per ChatRateWindow
+	ChatRateLimit  = 5
+	ChatRateWindow = 10 * time.Second
+)
+
+var (
+	ErrInvalidChatID   = errors.New("Invalid chat message id")
+	ErrChatNotFound    = errors.New("Chat message not found")
+	ErrChatTooLong     = fmt.Errorf("Chat message is longer than %d characters", MaxChatLength)
+	ErrChatRateLimited = errors.New("Too many chat messages, slow down")
+)
+
+// ChatMessage is a chat message as stored for a room, ID is given by the repository
+// and orders the messages of a room, SentAt is the server time in unix milliseconds.
+type ChatMessage struct {
+	ID      string `json:"id"`
+	Sender  string `json:"sender"`
+	Message string `json:"message"`
+	SentAt  int64  `json:"sentAt"`
+}
+
+func NewChatMessage(sender string, message string) ChatMessage {
+	return ChatMessage{
+		Sender:  sender,
+		Message: message,
+		SentAt:  time.Now().UnixMilli(),
+	}
+}
+
+// ValidChatID tells if id has the <milliseconds>-<sequence> form of chat message IDs
+func ValidChatID(id string) bool {
+	var ms, seq uint64
+	var rest string
+	n, _ := fmt.Sscanf(id, "%d-%d%s", &ms, &seq, &rest)
+	return n == 2
+}
+
+// ChatReport is a chat message a player reported for moderators to review,
+// it keeps a copy of the message since the chat of a room expires with the room.
+type ChatReport struct {
+	ID       string      `json:"id"`
+	RoomID   string      `json:"roomID"`
+	Reporter string      `json:"reporter"`
+	Reason   string      `json:"reason,omitempty"`
+	Message  ChatMessage `json:"message"`
+	At       int64       `json:"at"`
+}
+
+// ChatFilter masks blocked words in chat messages, words match whole and ignore case
+type ChatFilter struct {
+	words map[string]bool
+}
+
+func NewChatFilter(words []string) *ChatFilter {
+	f := &ChatFilter{words: make(map[string]bool)}
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			f.words[w] = true
+		}
+	}
+	return f
+}
+
+// ReadChatFilter reads one blocked word per line, empty lines and lines starting with # are skipped
+func ReadChatFilter(r io.Reader) (*ChatFilter, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewChatFilter(words), nil
+}
+
+// Mask replaces every letter of a blocked word with *, a nil filter blocks nothing
+func (f *ChatFilter) Mask(message string) string {
+	if f == nil || len(f.words) == 0 {
+		return message
+	}
+
+	runes := []rune(message)
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for start := 0; start < len(runes); {
+		if !isWord(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWord(runes[end]) {
+			end++
+		}
+		if f.words[strings.ToLower(string(runes[start:end]))] {
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+		start = end
+	}
+	return string(runes)
+}
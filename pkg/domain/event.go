@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventJoin       EventType = "JOIN"
+	EventCreate     EventType = "CREATE"
+	EventPlace      EventType = "PLACE"
+	EventShot       EventType = "SHOT"
+	EventSalvo      EventType = "SALVO"
+	EventTimeOut    EventType = "TIME_OUT"
+	EventDisconnect EventType = "DISCONNECT"
+	EventGameOver   EventType = "GAME_OVER"
+)
+
+var (
+	ErrGameNotCreated = errors.New("Game was never created")
+	ErrInvalidEvent   = errors.New("Invalid game event")
+)
+
+// Event is one state transition of a game, At is in unix milliseconds.
+// Replaying every event of a game through Apply gives back the game snapshot.
+type Event struct {
+	ID      string          `json:"id,omitempty"`
+	Type    EventType       `json:"type"`
+	Player  string          `json:"player,omitempty"`
+	Players []string        `json:"players,omitempty"`
+	Rules   *RuleSet        `json:"rules,omitempty"`
+	Ships   []ShipPlacement `json:"ships,omitempty"`
+	Shots   []Point         `json:"shots,omitempty"`
+	Winner  string          `json:"winner,omitempty"`
+	At      int64           `json:"at"`
+}
+
+func NewEvent(t EventType, playerID string) Event {
+	return Event{
+		Type:   t,
+		Player: playerID,
+		At:     time.Now().UnixMilli(),
+	}
+}
+
+// Apply runs the event through the same domain methods the game service uses
+func (g *Game) Apply(e Event) error {
+	at := time.UnixMilli(e.At)
+
+	switch e.Type {
+	case EventJoin, EventCreate:
+		// players joining and the game creation happen before the game exists
+
+	case EventPlace:
+		if err := g.AddShip(e.Player, e.Ships); err != nil {
+			return err
+		}
+		if g.AllShipsPlaced() {
+			g.Status = StatusActive
+			g.EndAt = at.Add(g.Rules.TurnDuration()).UnixMilli()
+		}
+
+	case EventShot:
+		if len(e.Shots) != 1 {
+			return ErrInvalidEvent
+		}
+		if _, err := g.HandleShot(e.Player, e.Shots[0]); err != nil {
+			return err
+		}
+		g.CheckWinner(e.Player)
+		g.EndAt = at.Add(g.Rules.TurnDuration()).UnixMilli()
+
+	case EventSalvo:
+		if _, err := g.HandleSalvo(e.Player, e.Shots); err != nil {
+			return err
+		}
+		g.CheckWinner(e.Player)
+		g.EndAt = at.Add(g.Rules.TurnDuration()).UnixMilli()
+
+	case EventTimeOut:
+		g.SwitchActivePlayer(g.ActivePlayer)
+		g.EndAt = at.Add(g.Rules.TurnDuration()).UnixMilli()
+
+	case EventDisconnect:
+		g.End(g.GetOpponent(e.Player))
+
+	case EventGameOver:
+		g.End(e.Winner)
+
+	default:
+		return ErrInvalidEvent
+	}
+	return nil
+}
+
+// Replay rebuilds a game from its event log, a rematch in the same room starts
+// with a new create event so the result is the latest round.
+func Replay(gameID string, events []Event) (*Game, error) {
+	var g *Game
+	round := 0
+
+	for _, e := range events {
+		if e.Type == EventCreate {
+			if len(e.Players) != 2 || e.Rules == nil {
+				return nil, ErrInvalidEvent
+			}
+			round++
+			g = NewGame(e.Players[0], e.Players[1], gameID, *e.Rules)
+			g.Round = round
+			g.EndAt = time.UnixMilli(e.At).Add(e.Rules.PlacementDuration()).UnixMilli()
+			continue
+		}
+
+		if g == nil {
+			if e.Type == EventJoin {
+				continue
+			}
+			return nil, ErrGameNotCreated
+		}
+
+		if err := g.Apply(e); err != nil {
+			return nil, err
+		}
+	}
+
+	if g == nil {
+		return nil, ErrGameNotCreated
+	}
+	return g, nil
+}
+
+// SplitRounds splits the event log of a room into one log per round,
+// events before the first create event belong to the first round.
+func SplitRounds(events []Event) [][]Event {
+	rounds := [][]Event{}
+	current := []Event{}
+	created := false
+
+	for _, e := range events {
+		if e.Type == EventCreate {
+			if created {
+				rounds = append(rounds, current)
+				current = []Event{}
+			}
+			created = true
+		}
+		current = append(current, e)
+	}
+
+	if len(current) > 0 {
+		rounds = append(rounds, current)
+	}
+	return rounds
+}
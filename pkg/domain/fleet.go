@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+)
+
+type Orientation string
+
+const (
+	Horizontal Orientation = "HORIZONTAL"
+	Vertical   Orientation = "VERTICAL"
+)
+
+// ShipClass is one entry of a fleet definition, e.g. a carrier of length 5.
+type ShipClass struct {
+	Name   string `json:"name"`
+	Length int    `json:"length"`
+}
+
+type Fleet []ShipClass
+
+// DefaultFleet is the classic battleship fleet
+var DefaultFleet = Fleet{
+	{Name: "carrier", Length: 5},
+	{Name: "battleship", Length: 4},
+	{Name: "cruiser", Length: 3},
+	{Name: "submarine", Length: 3},
+	{Name: "destroyer", Length: 2},
+}
+
+// ShipPlacement is what a player sends to place a single ship.
+// Origin is the first cell, a horizontal ship grows along Y and a vertical one along X
+// (boards are indexed as board[x][y]).
+type ShipPlacement struct {
+	Origin      Point       `json:"origin"`
+	Length      int         `json:"length"`
+	Orientation Orientation `json:"orientation"`
+}
+
+// PlacedShip is a ship that is on the board
+type PlacedShip struct {
+	Name  string  `json:"name"`
+	Cells []Point `json:"cells"`
+}
+
+// Cells returns every point covered by the placement
+func (s ShipPlacement) Cells() ([]Point, error) {
+	if s.Length <= 0 {
+		return nil, ErrInvalidShipPlacement
+	}
+
+	dx, dy := 0, 0
+	switch s.Orientation {
+	case Horizontal:
+		dy = 1
+	case Vertical:
+		dx = 1
+	default:
+		return nil, ErrInvalidOrientation
+	}
+
+	cells := make([]Point, s.Length)
+	for i := range cells {
+		cells[i] = Point{X: s.Origin.X + i*dx, Y: s.Origin.Y + i*dy}
+	}
+	return cells, nil
+}
+
+// TotalCells is the number of cells the whole fleet covers
+func (f Fleet) TotalCells() int {
+	total := 0
+	for _, c := range f {
+		total += c.Length
+	}
+	return total
+}
+
+// Layout packs the fleet into straight lanes along the longer side of a width x height
+// board, longest ships first. It is not a full search, RuleSet.Validate rejects fleets
+// Layout can not pack so every accepted rule set has at least this layout.
+func (f Fleet) Layout(width int, height int) ([]ShipPlacement, bool) {
+	lanes, size, orientation := height, width, Horizontal
+	if height > width {
+		lanes, size, orientation = width, height, Vertical
+	}
+
+	order := make([]int, len(f))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(f[b].Length, f[a].Length)
+	})
+
+	used := make([]int, lanes)
+	ships := make([]ShipPlacement, len(f))
+	for _, i := range order {
+		length := f[i].Length
+		lane := slices.IndexFunc(used, func(u int) bool { return u+length <= size })
+		if lane < 0 {
+			return nil, false
+		}
+		origin := Point{X: lane, Y: used[lane]}
+		if orientation == Vertical {
+			origin = Point{X: used[lane], Y: lane}
+		}
+		ships[i] = ShipPlacement{Origin: origin, Length: length, Orientation: orientation}
+		used[lane] += length
+	}
+	return ships, true
+}
+
+// match pairs every placement with a ship class of the same length,
+// each class can be used only once.
+func (f Fleet) match(ships []ShipPlacement) ([]ShipClass, error) {
+	if len(ships) != len(f) {
+		return nil, ErrInvalidFleet
+	}
+
+	used := make([]bool, len(f))
+	classes := make([]ShipClass, len(ships))
+
+	for i, s := range ships {
+		found := false
+		for j, c := range f {
+			if !used[j] && c.Length == s.Length {
+				used[j] = true
+				classes[i] = c
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrInvalidFleet
+		}
+	}
+	return classes, nil
+}
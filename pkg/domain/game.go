@@ -5,8 +5,6 @@ import (
 	"time"
 )
 
-var BoardSize = 5; //remember to take this from env file
-
 type CellState int
 
 const (
@@ -28,11 +26,16 @@ const (
 type Game struct {
 	ID				string						`json:"id"`
 	Boards			map[string][][]CellState    `json:"boards"`
+	Ships			map[string][]PlacedShip		`json:"ships"`
 	Players			[2]string					`json:"players"`
 	ActivePlayer	string						`json:"active_player"`
 	Winner 			string						`json:"winner"`
 	Status			GameStatus					`json:"status"`
 	EndAt           int64                       `json:"endAt"`
+	Rules			RuleSet						`json:"rules"`
+	Round			int							`json:"round"`
+	// Version counts the saves of the game, a save only succeeds on the version it was loaded at
+	Version			int64						`json:"version"`
 
 }
 
@@ -47,13 +50,15 @@ var (
 	ErrBoardNotFound = errors.New("board not found")
 	ErrGameNotStarted = errors.New("Game has not started yet")
 	ErrShipPlaced = errors.New("Not Allowed to place Ship")
-	ErrShipLimitExceed = errors.New("Ship max/min limit is 5")
+	ErrInvalidFleet = errors.New("Ships do not match the fleet")
 	ErrInvalidShipPlacement = errors.New("Invalid Ship Placement")
+	ErrInvalidOrientation = errors.New("Invalid Ship Orientation")
 	ErrGameOver = errors.New("Game is Finished")
 	ErrInvalidMove = errors.New("Invalid Move")
+	ErrVersionConflict = errors.New("Game was changed by another request")
 )
 
-func NewGame(P1 , P2, roomId string) (*Game) {
+func NewGame(P1 , P2, roomId string, rules RuleSet) (*Game) {
 
 	g := &Game{
 		ID: roomId,
@@ -62,13 +67,16 @@ func NewGame(P1 , P2, roomId string) (*Game) {
 		Winner: "",
 		Status: StatusWait,
 		EndAt: -1,
+		Ships: make(map[string][]PlacedShip),
+		Rules: rules,
+		Round: 1,
 	}
 	BoardsTemp := make(map[string][][]CellState)
 	for _ ,i := range g.Players {
 		
-		PlayerBoard :=  make([][]CellState,BoardSize)
+		PlayerBoard :=  make([][]CellState,rules.Height)
 		for j:=range PlayerBoard {
-			PlayerBoard[j]  = make([]CellState, BoardSize)
+			PlayerBoard[j]  = make([]CellState, rules.Width)
 			for k:= range PlayerBoard[j]{
 				PlayerBoard[j][k] = Empty
 			}
@@ -92,11 +100,15 @@ func (g *Game) HandleShot(playerID string, p Point) (CellState,error) {
 		return Empty, ErrGameNotStarted
 	}
 
+	if g.Rules.Mode == ModeSalvo {
+		return Empty, ErrWrongMode
+	}
+
 	if g.ActivePlayer != playerID {
 		return Empty, ErrNotYourTurn
 	}
 
-	if p.X < 0 || p.X >= BoardSize || p.Y < 0 || p.Y >= BoardSize {
+	if !g.Rules.InBounds(p) {
 		return Empty ,ErrOutOfBound
 	}
 
@@ -113,6 +125,9 @@ func (g *Game) HandleShot(playerID string, p Point) (CellState,error) {
 
 	if board[p.X][p.Y] == Ship {
 		board[p.X][p.Y] = Hit
+		if !g.Rules.ExtraShotOnHit {
+			g.ActivePlayer = opponentID
+		}
 		return Hit,nil
 	}
 
@@ -125,29 +140,57 @@ func (g *Game) HandleShot(playerID string, p Point) (CellState,error) {
 
 func (g  *Game) CheckWinner(playerID string) bool {
 	opponentID := g.GetOpponent(playerID)
-	board:= g.Boards[opponentID]
-	countShip := 0
-	countHit := 0
-	for i:= range board {
-		for j := range board[i] {
-			if board[i][j] == Hit {
-				countHit++
-			}
-			if board[i][j] == Ship {
-				countShip++
-			}
-		}
+	ships := g.Ships[opponentID]
+
+	if len(ships) == 0 {
+		return false
 	}
 
-	if countShip!=0 || countHit == 0 {
-		return  false
+	for _, s := range ships {
+		if !g.isSunk(opponentID, s) {
+			return false
+		}
 	}
 	
 	g.Status = StatusOver
+	g.Winner = playerID
 	return true
 
 }
 
+// SunkShip returns the ship of ownerID covering p if every cell of it has been hit
+func (g *Game) SunkShip(ownerID string, p Point) (PlacedShip, bool) {
+	for _, s := range g.Ships[ownerID] {
+		for _, c := range s.Cells {
+			if c == p {
+				return s, g.isSunk(ownerID, s)
+			}
+		}
+	}
+	return PlacedShip{}, false
+}
+
+// SunkShips returns every ship of ownerID that has been sunk so far
+func (g *Game) SunkShips(ownerID string) []PlacedShip {
+	sunk := []PlacedShip{}
+	for _, s := range g.Ships[ownerID] {
+		if g.isSunk(ownerID, s) {
+			sunk = append(sunk, s)
+		}
+	}
+	return sunk
+}
+
+func (g *Game) isSunk(ownerID string, s PlacedShip) bool {
+	board := g.Boards[ownerID]
+	for _, c := range s.Cells {
+		if board[c.X][c.Y] != Hit {
+			return false
+		}
+	}
+	return true
+}
+
 func (g *Game) GetOpponent(Player string) string {
 	if Player != g.Players[0] {
 		return g.Players[0]
@@ -155,56 +198,115 @@ func (g *Game) GetOpponent(Player string) string {
 	return g.Players[1]
 }
 
-func (g *Game) AddShip(playerID string, Ships []Point) error {
+func (g *Game) AddShip(playerID string, ships []ShipPlacement) error {
 
 	if g.Status!=StatusWait {
 		return ErrShipPlaced
 	}
-	board := g.Boards[playerID]
 
-	if len(Ships)!=BoardSize{
-		return ErrShipLimitExceed
+	board, ok := g.Boards[playerID]
+	if !ok {
+		return ErrBoardNotFound
 	}
 
-	for i:= range Ships {
-		x := Ships[i].X
-		y := Ships[i].Y
+	if len(g.Ships[playerID]) != 0 {
+		return ErrShipPlaced
+	}
+
+	classes, err := g.Rules.Fleet.match(ships)
+	if err != nil {
+		return err
+	}
 
-		if x < 0 || x>= BoardSize || y < 0 || y>=BoardSize {
-			return ErrOutOfBound
+	// validate everything first so a bad ship does not leave the board half filled
+	occupied := make(map[Point]bool)
+	placed := make([]PlacedShip, len(ships))
+
+	for i := range ships {
+		cells, err := ships[i].Cells()
+		if err != nil {
+			return err
 		}
 
-		if board[x][y] == Ship {
-			return ErrInvalidShipPlacement
+		for _, c := range cells {
+			if !g.Rules.InBounds(c) {
+				return ErrOutOfBound
+			}
+
+			if occupied[c] || board[c.X][c.Y] == Ship {
+				return ErrInvalidShipPlacement
+			}
+			occupied[c] = true
 		}
 
-		board[x][y] = Ship
+		placed[i] = PlacedShip{Name: classes[i].Name, Cells: cells}
+	}
+
+	for c := range occupied {
+		board[c.X][c.Y] = Ship
+	}
+
+	if g.Ships == nil {
+		g.Ships = make(map[string][]PlacedShip)
 	}
 
 	g.Boards[playerID] = board
+	g.Ships[playerID] = placed
 
 	return nil
 }
 
+// AllShipsPlaced reports whether both players have placed their fleet
+func (g *Game) AllShipsPlaced() bool {
+	for _, p := range g.Players {
+		if len(g.Ships[p]) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Rematch returns a fresh game between the same players in the same room,
+// the players swap seats so the one who did not start this round starts the next.
+func (g *Game) Rematch(rules RuleSet) *Game {
+	next := NewGame(g.Players[1], g.Players[0], g.ID, rules)
+	next.Round = g.Round + 1
+	// the next round replaces this game, so it is saved on top of its version
+	next.Version = g.Version
+	return next
+}
+
+// End finishes the game, an empty winner means nobody won
+func (g *Game) End(winner string) {
+	g.Winner = winner
+	g.Status = StatusOver
+}
+
 func (g *Game) SwitchActivePlayer(playerID string)  {
 	opponentID := g.GetOpponent(playerID)
 	g.ActivePlayer = opponentID
 }
 
+// HideOpponentShips returns a copy of the opponent board with ship cells shown as empty
 func (g *Game) HideOpponentShips(playerID string) [][]CellState {
-	
-	opponentId := g.GetOpponent(playerID)
+	return g.HiddenBoard(g.GetOpponent(playerID))
+}
+
+// HiddenBoard returns a copy of the board of ownerID that only shows hits and misses
+func (g *Game) HiddenBoard(ownerID string) [][]CellState {
 
-	board := g.Boards[opponentId]  
+	board := g.Boards[ownerID]  
+	hidden := make([][]CellState, len(board))
 
 	for i := range board {
+		hidden[i] = make([]CellState, len(board[i]))
 		for j:= range board[i] {
-			if board[i][j] == Ship {
-				board[i][j] =  Empty
+			if board[i][j] != Ship {
+				hidden[i][j] = board[i][j]
 			}
 		}
 	}
-	return board
+	return hidden
 
 }
 
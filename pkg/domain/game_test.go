@@ -1,7 +1,11 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func assertLogError(t *testing.T, name, expected, got any) {
@@ -24,7 +28,7 @@ func TestNewGame(t *testing.T) {
 	player1 := "A"
 	player2 := "B"
 	roomId := "123"
-	game := NewGame(player1, player2, roomId)
+	game := NewGame(player1, player2, roomId, DefaultRuleSet())
 
 	assertLogError(t, "roomId", roomId, game.ID)
 	assertLogError(t, "Active player", player1, game.ActivePlayer)
@@ -34,3 +38,481 @@ func TestNewGame(t *testing.T) {
 	assertEmptyBoardCheck(t, game.Boards[player2])
 }
 
+func classicPlacement() []ShipPlacement {
+	return []ShipPlacement{
+		{Origin: Point{X: 0, Y: 0}, Length: 5, Orientation: Horizontal},
+		{Origin: Point{X: 1, Y: 0}, Length: 4, Orientation: Horizontal},
+		{Origin: Point{X: 2, Y: 0}, Length: 3, Orientation: Horizontal},
+		{Origin: Point{X: 3, Y: 0}, Length: 3, Orientation: Horizontal},
+		{Origin: Point{X: 5, Y: 5}, Length: 2, Orientation: Vertical},
+	}
+}
+
+func TestAddShip(t *testing.T) {
+	game := NewGame("A", "B", "123", DefaultRuleSet())
+
+	if err := game.AddShip("A", classicPlacement()); err != nil {
+		t.Fatalf("expected valid placement but got %v", err)
+	}
+
+	board := game.Boards["A"]
+	assertLogError(t, "carrier end", Ship, board[0][4])
+	assertLogError(t, "destroyer end", Ship, board[6][5])
+	assertLogError(t, "empty cell", Empty, board[0][5])
+	assertLogError(t, "ships placed", len(DefaultFleet), len(game.Ships["A"]))
+	assertLogError(t, "ship name", "carrier", game.Ships["A"][0].Name)
+
+	assertLogError(t, "second placement", ErrShipPlaced, game.AddShip("A", classicPlacement()))
+}
+
+func TestAddShipInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func([]ShipPlacement)
+		err    error
+	}{
+		{"overlap", func(s []ShipPlacement) { s[1].Origin = Point{X: 0, Y: 1} }, ErrInvalidShipPlacement},
+		{"out of bound", func(s []ShipPlacement) { s[0].Origin = Point{X: 0, Y: DefaultRuleSet().Width - 2} }, ErrOutOfBound},
+		{"wrong length", func(s []ShipPlacement) { s[4].Length = 6 }, ErrInvalidFleet},
+		{"bad orientation", func(s []ShipPlacement) { s[2].Orientation = "DIAGONAL" }, ErrInvalidOrientation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewGame("A", "B", "123", DefaultRuleSet())
+			ships := classicPlacement()
+			tt.modify(ships)
+
+			assertLogError(t, "error", tt.err, game.AddShip("A", ships))
+			assertEmptyBoardCheck(t, game.Boards["A"])
+		})
+	}
+
+	game := NewGame("A", "B", "123", DefaultRuleSet())
+	assertLogError(t, "missing ship", ErrInvalidFleet, game.AddShip("A", classicPlacement()[:4]))
+}
+
+func TestSunkShipAndWinner(t *testing.T) {
+	game := NewGame("A", "B", "123", DefaultRuleSet())
+	game.AddShip("A", classicPlacement())
+	game.AddShip("B", classicPlacement())
+	game.Status = StatusActive
+
+	// destroyer is the vertical ship at (5,5)-(6,5)
+	game.ActivePlayer = "A"
+	game.HandleShot("A", Point{X: 5, Y: 5})
+	if _, sunk := game.SunkShip("B", Point{X: 5, Y: 5}); sunk {
+		t.Fatalf("expected destroyer afloat after one hit")
+	}
+
+	game.ActivePlayer = "A"
+	game.HandleShot("A", Point{X: 6, Y: 5})
+	ship, sunk := game.SunkShip("B", Point{X: 6, Y: 5})
+	if !sunk {
+		t.Fatalf("expected destroyer to be sunk")
+	}
+	assertLogError(t, "sunk ship", "destroyer", ship.Name)
+	assertLogError(t, "sunk ships", 1, len(game.SunkShips("B")))
+	assertLogError(t, "winner early", false, game.CheckWinner("A"))
+
+	for _, s := range game.Ships["B"] {
+		for _, c := range s.Cells {
+			game.ActivePlayer = "A"
+			game.HandleShot("A", c)
+		}
+	}
+
+	assertLogError(t, "winner", true, game.CheckWinner("A"))
+	assertLogError(t, "winner id", "A", game.Winner)
+	assertLogError(t, "status", StatusOver, game.Status)
+}
+
+func TestRuleSetValidate(t *testing.T) {
+	assertLogError(t, "default rules", nil, DefaultRuleSet().Validate())
+
+	small := DefaultRuleSet()
+	small.Width = 4
+	assertLogError(t, "small board", ErrInvalidBoardSize, small.Validate())
+
+	long := DefaultRuleSet()
+	long.Width, long.Height = 6, 6
+	long.Fleet = Fleet{{Name: "carrier", Length: 7}}
+	assertLogError(t, "ship too long", ErrInvalidFleet, long.Validate())
+
+	// fits by area, but three ships of 5 and one of 4 leave no line for the ships of 3
+	crowded := DefaultRuleSet()
+	crowded.Width, crowded.Height = 5, 5
+	crowded.Fleet = Fleet{{Name: "a", Length: 5}, {Name: "b", Length: 5}, {Name: "c", Length: 5}, {Name: "d", Length: 4}, {Name: "e", Length: 3}, {Name: "f", Length: 3}}
+	assertLogError(t, "no layout", ErrInvalidFleet, crowded.Validate())
+
+	fast := DefaultRuleSet()
+	fast.TurnLimit = 1
+	assertLogError(t, "turn limit", ErrInvalidTimeLimit, fast.Validate())
+
+	slow := DefaultRuleSet()
+	slow.TurnLimit, slow.PlacementLimit = 300, 600
+	assertLogError(t, "longest limits", nil, slow.Validate())
+	assertLogError(t, "game ttl", 10*time.Minute+GameGrace, slow.GameTTL())
+}
+
+func TestCustomRuleSet(t *testing.T) {
+	rules := DefaultRuleSet()
+	rules.Width, rules.Height = 6, 5
+	rules.Fleet = Fleet{{Name: "destroyer", Length: 2}}
+	rules.ExtraShotOnHit = true
+
+	game := NewGame("A", "B", "123", rules)
+	assertLogError(t, "rows", 5, len(game.Boards["A"]))
+	assertLogError(t, "columns", 6, len(game.Boards["A"][0]))
+
+	ship := []ShipPlacement{{Origin: Point{X: 4, Y: 4}, Length: 2, Orientation: Horizontal}}
+	assertLogError(t, "place A", nil, game.AddShip("A", ship))
+	assertLogError(t, "place B", nil, game.AddShip("B", ship))
+	game.Status = StatusActive
+
+	_, err := game.HandleShot("A", Point{X: 5, Y: 0})
+	assertLogError(t, "out of bound", ErrOutOfBound, err)
+
+	game.HandleShot("A", Point{X: 4, Y: 4})
+	assertLogError(t, "extra shot on hit", "A", game.ActivePlayer)
+
+	game.HandleShot("A", Point{X: 0, Y: 0})
+	assertLogError(t, "turn after miss", "B", game.ActivePlayer)
+}
+
+func TestHandleSalvo(t *testing.T) {
+	rules := DefaultRuleSet()
+	rules.Mode = ModeSalvo
+
+	game := NewGame("A", "B", "123", rules)
+	game.AddShip("A", classicPlacement())
+	game.AddShip("B", classicPlacement())
+	game.Status = StatusActive
+
+	_, err := game.HandleShot("A", Point{X: 0, Y: 0})
+	assertLogError(t, "single shot in salvo mode", ErrWrongMode, err)
+
+	assertLogError(t, "salvo size", 5, game.SalvoSize("A"))
+
+	_, err = game.HandleSalvo("A", []Point{{X: 5, Y: 5}, {X: 6, Y: 5}})
+	assertLogError(t, "short salvo", ErrInvalidSalvo, err)
+
+	_, err = game.HandleSalvo("A", []Point{{X: 5, Y: 5}, {X: 5, Y: 5}, {X: 9, Y: 9}, {X: 9, Y: 8}, {X: 9, Y: 7}})
+	assertLogError(t, "duplicate shot", ErrInvalidMove, err)
+	assertLogError(t, "untouched board", Ship, game.Boards["B"][5][5])
+
+	result, err := game.HandleSalvo("A", []Point{{X: 5, Y: 5}, {X: 6, Y: 5}, {X: 9, Y: 9}, {X: 9, Y: 8}, {X: 0, Y: 0}})
+	assertLogError(t, "salvo error", nil, err)
+	assertLogError(t, "shots", 5, len(result.Shots))
+	assertLogError(t, "miss", Miss, result.Shots[2].Result)
+	assertLogError(t, "hit", Hit, result.Shots[4].Result)
+	assertLogError(t, "sunk", 1, len(result.Sunk))
+	assertLogError(t, "sunk name", "destroyer", result.Sunk[0].Name)
+	assertLogError(t, "next turn", "B", game.ActivePlayer)
+	assertLogError(t, "opponent salvo size", 4, game.SalvoSize("B"))
+}
+
+func TestReplay(t *testing.T) {
+	rules := DefaultRuleSet()
+	at := int64(1_700_000_000_000)
+	event := func(e Event) Event {
+		at += 1000
+		e.At = at
+		return e
+	}
+
+	events := []Event{
+		event(Event{Type: EventJoin, Player: "A"}),
+		event(Event{Type: EventJoin, Player: "B"}),
+		event(Event{Type: EventCreate, Players: []string{"A", "B"}, Rules: &rules}),
+		event(Event{Type: EventPlace, Player: "A", Ships: classicPlacement()}),
+		event(Event{Type: EventPlace, Player: "B", Ships: classicPlacement()}),
+		event(Event{Type: EventShot, Player: "A", Shots: []Point{{X: 5, Y: 5}}}),
+		event(Event{Type: EventTimeOut, Player: "B"}),
+		event(Event{Type: EventShot, Player: "A", Shots: []Point{{X: 9, Y: 9}}}),
+	}
+
+	game, err := Replay("123", events)
+	assertLogError(t, "replay error", nil, err)
+	assertLogError(t, "status", StatusActive, game.Status)
+	assertLogError(t, "hit", Hit, game.Boards["B"][5][5])
+	assertLogError(t, "miss", Miss, game.Boards["B"][9][9])
+	assertLogError(t, "active player", "B", game.ActivePlayer)
+	assertLogError(t, "end at", at+int64(rules.TurnLimit)*1000, game.EndAt)
+
+	events = append(events, event(Event{Type: EventDisconnect, Player: "B"}))
+	game, err = Replay("123", events)
+	assertLogError(t, "replay error", nil, err)
+	assertLogError(t, "status", StatusOver, game.Status)
+	assertLogError(t, "winner", "A", game.Winner)
+
+	_, err = Replay("123", events[:2])
+	assertLogError(t, "not created", ErrGameNotCreated, err)
+
+	_, err = Replay("123", append(events[:5:5], event(Event{Type: EventShot, Player: "B", Shots: []Point{{X: 0, Y: 0}}})))
+	assertLogError(t, "invalid shot", ErrNotYourTurn, err)
+}
+
+func TestRematch(t *testing.T) {
+	game := NewGame("A", "B", "123", DefaultRuleSet())
+	game.End("A")
+
+	next := game.Rematch(game.Rules)
+	assertLogError(t, "room", "123", next.ID)
+	assertLogError(t, "round", 2, next.Round)
+	assertLogError(t, "starter swapped", "B", next.ActivePlayer)
+	assertLogError(t, "status", StatusWait, next.Status)
+	assertLogError(t, "winner", "", next.Winner)
+	assertEmptyBoardCheck(t, next.Boards["A"])
+
+	rules := DefaultRuleSet()
+	events := []Event{
+		{Type: EventJoin, Player: "A"},
+		{Type: EventCreate, Players: []string{"A", "B"}, Rules: &rules},
+		{Type: EventGameOver},
+		{Type: EventCreate, Players: []string{"B", "A"}, Rules: &rules},
+	}
+
+	rounds := SplitRounds(events)
+	assertLogError(t, "rounds", 2, len(rounds))
+	assertLogError(t, "first round events", 3, len(rounds[0]))
+
+	latest, err := Replay("123", events)
+	assertLogError(t, "replay error", nil, err)
+	assertLogError(t, "latest round", 2, latest.Round)
+	assertLogError(t, "latest starter", "B", latest.ActivePlayer)
+}
+
+func TestSeries(t *testing.T) {
+	assertLogError(t, "invalid bestOf", ErrInvalidBestOf, ValidateBestOf(4))
+	assertLogError(t, "valid bestOf", nil, ValidateBestOf(3))
+
+	series := NewSeries(3)
+	assertLogError(t, "first round", true, series.Record(1, "A"))
+	assertLogError(t, "round counted twice", false, series.Record(1, "A"))
+	assertLogError(t, "draw", true, series.Record(2, ""))
+	assertLogError(t, "not decided", false, series.Decided())
+
+	series.Record(3, "A")
+	assertLogError(t, "decided", true, series.Decided())
+	assertLogError(t, "winner", "A", series.Winner)
+	assertLogError(t, "games", 3, series.Games)
+	assertLogError(t, "after decided", false, series.Record(4, "B"))
+
+	series.Reset()
+	assertLogError(t, "reset winner", "", series.Winner)
+	assertLogError(t, "next series", true, series.Record(4, "B"))
+	assertLogError(t, "score", 1, series.Scores["B"])
+}
+
+func TestChatFilter(t *testing.T) {
+	filter, err := ReadChatFilter(strings.NewReader("# blocked words\nDarn\n\nheck\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in, out string
+	}{
+		{"darn it", "**** it"},
+		{"What the HECK!", "What the ****!"},
+		{"darnation is fine", "darnation is fine"},
+		{"heck,darn.", "****,****."},
+	}
+	for _, tt := range tests {
+		if got := filter.Mask(tt.in); got != tt.out {
+			t.Errorf("Mask(%q): expected %q, got %q", tt.in, tt.out, got)
+		}
+	}
+
+	var none *ChatFilter
+	if got := none.Mask("darn"); got != "darn" {
+		t.Errorf("nil filter: expected the message unchanged, got %q", got)
+	}
+}
+
+func TestEloDeltas(t *testing.T) {
+	even := NewRating()
+	if gain, loss := EloDeltas(even, even); gain != 20 || loss != -20 {
+		t.Errorf("even ratings: expected +20 -20, got %+d %+d", gain, loss)
+	}
+
+	strong := Rating{Points: 1600, Games: provisionalGames}
+	gain, loss := EloDeltas(strong, even)
+	if gain <= 0 || gain >= 5 || loss != -2*gain {
+		t.Errorf("favourite wins: expected a small gain and twice the loss for the new player, got %+d %+d", gain, loss)
+	}
+
+	gain, loss = EloDeltas(even, strong)
+	if gain <= 30 || loss >= -15 {
+		t.Errorf("upset: expected a big gain, got %+d %+d", gain, loss)
+	}
+
+	even.Add(gain, true)
+	if even.Points != DefaultRating+gain || even.Games != 1 || even.Wins != 1 || even.Losses != 0 {
+		t.Errorf("rating after a win: got %+v", even)
+	}
+}
+
+func TestSeasons(t *testing.T) {
+	seasons, err := ReadSeasons(strings.NewReader(`[
+		{"id": "s2", "start": "2026-04-01T00:00:00Z", "end": "2026-07-01T00:00:00Z"},
+		{"id": "s1", "name": "Launch", "start": "2026-01-01T00:00:00Z", "end": "2026-04-01T00:00:00Z"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seasons[0].ID != "s1" || seasons[1].ID != "s2" {
+		t.Errorf("expected seasons ordered by start, got %+v", seasons)
+	}
+
+	at := func(s string) time.Time {
+		ts, _ := time.Parse(time.RFC3339, s)
+		return ts
+	}
+	if s, ok := SeasonAt(seasons, at("2026-04-01T00:00:00Z")); !ok || s.ID != "s2" {
+		t.Errorf("season at the rollover: expected s2, got %+v %v", s, ok)
+	}
+	if _, ok := SeasonAt(seasons, at("2026-08-01T00:00:00Z")); ok {
+		t.Error("expected no season after the last one")
+	}
+
+	for _, bad := range []string{
+		`[{"id": "s1", "start": "2026-04-01T00:00:00Z", "end": "2026-01-01T00:00:00Z"}]`,
+		`[{"id": "s 1", "start": "2026-01-01T00:00:00Z", "end": "2026-04-01T00:00:00Z"}]`,
+		`[{"id": "s1", "start": "2026-01-01T00:00:00Z", "end": "2026-04-01T00:00:00Z"},
+		  {"id": "s2", "start": "2026-03-01T00:00:00Z", "end": "2026-05-01T00:00:00Z"}]`,
+	} {
+		if _, err := ReadSeasons(strings.NewReader(bad)); !errors.Is(err, ErrInvalidSeason) {
+			t.Errorf("%s: expected %v, got %v", bad, ErrInvalidSeason, err)
+		}
+	}
+
+	if r := (Rating{Points: 1500, Games: 40}).SoftReset(); r.Points != 1350 || r.Games != 40 {
+		t.Errorf("soft reset of 1500: got %+v", r)
+	}
+	if r := (Rating{Points: 1001}).SoftReset(); r.Points != 1101 {
+		t.Errorf("soft reset of 1001: got %+v", r)
+	}
+}
+
+func TestTournamentBracket(t *testing.T) {
+	seed := func(player string) int {
+		var i int
+		fmt.Sscanf(player, "p%d", &i)
+		return i
+	}
+
+	for _, format := range []TournamentFormat{SingleElimination, DoubleElimination} {
+		for n := MinTournamentPlayers; n <= 17; n++ {
+			tour, err := NewTournament("t", "Cup", "org", format, 0, DefaultRuleSet(), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < n; i++ {
+				if err := tour.Register(fmt.Sprintf("p%d", i)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tour.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			// the lower seed always wins, so p0 has to win every tournament
+			losses := make(map[string]int)
+			for played := 0; tour.Status == TournamentRunning; played++ {
+				ready := tour.Playable()
+				if len(ready) == 0 || played > 4*n {
+					t.Fatalf("%s with %d players: stuck at %+v", format, n, tour.Matches)
+				}
+				m := ready[0]
+				winner, loser := m.Players[0], m.Players[1]
+				if seed(winner) > seed(loser) {
+					winner, loser = loser, winner
+				}
+				if err := tour.Report(m.ID, winner); err != nil {
+					t.Fatal(err)
+				}
+				losses[loser]++
+			}
+
+			if tour.Winner != "p0" {
+				t.Errorf("%s with %d players: expected p0 to win, got %q", format, n, tour.Winner)
+			}
+			want := 1
+			if format == DoubleElimination {
+				want = 2
+			}
+			for i := 1; i < n; i++ {
+				if p := fmt.Sprintf("p%d", i); losses[p] != want {
+					t.Errorf("%s with %d players: %s lost %d times, expected %d", format, n, p, losses[p], want)
+				}
+			}
+		}
+	}
+
+	// the losers bracket finalist p1 wins the first grand final, the champion p0 has
+	// lost once only and gets the reset final
+	for _, resetWinner := range []string{"p0", "p1"} {
+		tour, _ := NewTournament("t", "Cup", "org", DoubleElimination, 0, DefaultRuleSet(), 1)
+		for i := 0; i < 5; i++ {
+			tour.Register(fmt.Sprintf("p%d", i))
+		}
+		tour.Start()
+
+		losses := make(map[string]int)
+		for played := 0; tour.Status == TournamentRunning; played++ {
+			ready := tour.Playable()
+			if len(ready) == 0 || played > 20 {
+				t.Fatalf("reset final: stuck at %+v", tour.Matches)
+			}
+			m := ready[0]
+			winner, loser := m.Players[0], m.Players[1]
+			switch {
+			case m.ID == "F1-1":
+				winner, loser = loser, winner
+			case m.ID == "F2-1":
+				if winner != resetWinner {
+					winner, loser = loser, winner
+				}
+			case seed(winner) > seed(loser):
+				winner, loser = loser, winner
+			}
+			if err := tour.Report(m.ID, winner); err != nil {
+				t.Fatal(err)
+			}
+			losses[loser]++
+		}
+
+		if f2 := tour.Match("F2-1"); f2 == nil || f2.Players != [2]string{"p0", "p1"} {
+			t.Fatalf("reset final: expected p0 and p1, got %+v", f2)
+		}
+		if tour.Winner != resetWinner || losses["p0"]+losses["p1"] != 3 || losses[resetWinner] != 1 {
+			t.Errorf("reset final: expected %s to win with one loss, got %q and losses %v", resetWinner, tour.Winner, losses)
+		}
+	}
+
+	tour, _ := NewTournament("t", "Cup", "org", SingleElimination, 2, DefaultRuleSet(), 1)
+	tour.Register("a")
+	if err := tour.Register("a"); !errors.Is(err, ErrAlreadyRegistered) {
+		t.Errorf("register twice: expected %v, got %v", ErrAlreadyRegistered, err)
+	}
+	if err := tour.Start(); !errors.Is(err, ErrNotEnoughPlayers) {
+		t.Errorf("start alone: expected %v, got %v", ErrNotEnoughPlayers, err)
+	}
+	tour.Register("b")
+	if err := tour.Register("c"); !errors.Is(err, ErrTournamentFull) {
+		t.Errorf("register past max: expected %v, got %v", ErrTournamentFull, err)
+	}
+	tour.Start()
+	if err := tour.Report("W1-1", "c"); !errors.Is(err, ErrNotInMatch) {
+		t.Errorf("report outsider: expected %v, got %v", ErrNotInMatch, err)
+	}
+	tour.Report("W1-1", "b")
+	if err := tour.Report("W1-1", "a"); !errors.Is(err, ErrMatchNotPlayable) {
+		t.Errorf("report twice: expected %v, got %v", ErrMatchNotPlayable, err)
+	}
+	if tour.Status != TournamentFinished || tour.Winner != "b" {
+		t.Errorf("expected b to win the final, got %+v", tour)
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Board is what a leaderboard ranks players by
+type Board string
+
+const (
+	BoardRating Board = "rating"
+	BoardWins   Board = "wins"
+	// BoardStreak ranks by the longest run of ranked wins
+	BoardStreak Board = "streak"
+)
+
+// GlobalScope holds the leaderboards over all seasons
+const GlobalScope = "global"
+
+const (
+	LeaderboardPageSize    = 20
+	MaxLeaderboardPageSize = 100
+)
+
+var (
+	ErrInvalidBoard = errors.New("Board must be rating, wins or streak")
+	ErrNotRanked    = errors.New("Player is not on the leaderboard")
+)
+
+// LeaderboardEntry is the place of a player on a board, Rank starts at 1
+type LeaderboardEntry struct {
+	Rank     int64  `json:"rank"`
+	PlayerID string `json:"playerID"`
+	Name     string `json:"name,omitempty"`
+	Score    int    `json:"score"`
+}
+
+// ParseBoard validates a requested board, the rating board is the default
+func ParseBoard(b string) (Board, error) {
+	switch Board(strings.ToLower(b)) {
+	case "", BoardRating:
+		return BoardRating, nil
+	case BoardWins:
+		return BoardWins, nil
+	case BoardStreak:
+		return BoardStreak, nil
+	}
+	return "", ErrInvalidBoard
+}
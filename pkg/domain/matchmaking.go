@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRegion = errors.New("Region must be up to 16 letters, digits or -")
+
+var regionPattern = regexp.MustCompile(`^[a-z0-9-]{0,16}$`)
+
+// QueueEntry is a player waiting in the matchmaking queue, EnqueuedAt is in unix milliseconds.
+// An empty Region plays against every region.
+type QueueEntry struct {
+	PlayerID   string `json:"playerID"`
+	EnqueuedAt int64  `json:"enqueuedAt"`
+	Rating     int    `json:"rating"`
+	Region     string `json:"region,omitempty"`
+}
+
+func NewQueueEntry(playerID string, rating int, region string) (QueueEntry, error) {
+	region = strings.ToLower(strings.TrimSpace(region))
+	if !regionPattern.MatchString(region) {
+		return QueueEntry{}, ErrInvalidRegion
+	}
+	return QueueEntry{
+		PlayerID:   playerID,
+		EnqueuedAt: time.Now().UnixMilli(),
+		Rating:     rating,
+		Region:     region,
+	}, nil
+}
+
+// Waited is how long the player has been in the queue at now
+func (e QueueEntry) Waited(now time.Time) time.Duration {
+	return now.Sub(time.UnixMilli(e.EnqueuedAt))
+}
+
+// SameRegion tells if two players are in the same region, no region matches any
+func (e QueueEntry) SameRegion(other QueueEntry) bool {
+	return e.Region == "" || other.Region == "" || e.Region == other.Region
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "math"
+
+const (
+	// DefaultRating is the Elo rating every user starts with
+	DefaultRating = 1200
+	// provisionalGames is how many games a rating moves fast to find its level
+	provisionalGames = 30
+	provisionalK     = 40
+	establishedK     = 20
+)
+
+// Rating is the Elo rating of a user, a game counts once it is won or lost
+type Rating struct {
+	Points int `json:"rating"`
+	Games  int `json:"games"`
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+}
+
+func NewRating() Rating {
+	return Rating{Points: DefaultRating}
+}
+
+// k is how far a single game can move the rating
+func (r Rating) k() float64 {
+	if r.Games < provisionalGames {
+		return provisionalK
+	}
+	return establishedK
+}
+
+// Add counts a game that changed the rating by delta
+func (r *Rating) Add(delta int, won bool) {
+	r.Points += delta
+	r.Games++
+	if won {
+		r.Wins++
+	} else {
+		r.Losses++
+	}
+}
+
+// EloDeltas returns how much the winner gains and the loser loses of their rating,
+// each side moves by its own K so new players settle faster than established ones
+func EloDeltas(winner Rating, loser Rating) (gain int, loss int) {
+	expected := 1 / (1 + math.Pow(10, float64(loser.Points-winner.Points)/400))
+
+	gain = int(math.Round(winner.k() * (1 - expected)))
+	loss = int(math.Round(loser.k() * (1 - expected)))
+	return gain, -loss
+}
@@ -3,8 +3,49 @@ package domain
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"time"
 )
 
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "PUBLIC"
+	VisibilityPrivate Visibility = "PRIVATE"
+)
+
+type RoomStatus string
+
+const (
+	RoomOpen     RoomStatus = "OPEN"
+	RoomPlaying  RoomStatus = "PLAYING"
+	RoomFinished RoomStatus = "FINISHED"
+)
+
+// RoomTTL is how long a room lives without any activity before it is removed
+const RoomTTL = 30 * time.Minute
+
+var (
+	ErrInvalidVisibility = errors.New("visibility must be PUBLIC or PRIVATE")
+	ErrRoomNotFound      = errors.New("Room not found")
+)
+
+// Room is the record of a room, it outlives the games played in it. Bot holds the
+// bot difficulty of rooms against the AI and ExpiresAt is filled in when the room is read.
+type Room struct {
+	ID         string     `json:"id"`
+	Creator    string     `json:"creator,omitempty"`
+	CreatedAt  int64      `json:"createdAt"`
+	Rules      RuleSet    `json:"rules"`
+	Visibility Visibility `json:"visibility"`
+	Status     RoomStatus `json:"status"`
+	Bot        string     `json:"bot,omitempty"`
+	BestOf     int        `json:"bestOf"`
+	ExpiresAt  int64      `json:"expiresAt"`
+	// Tournament and Seats are set for the rooms of tournament matches, only the seated players may join
+	Tournament string   `json:"tournament,omitempty"`
+	Seats      []string `json:"seats,omitempty"`
+}
 
 func GenerateRoomID(length int) (string, error) {
 	bytes := make([]byte, length)
@@ -13,4 +54,38 @@ func GenerateRoomID(length int) (string, error) {
 		return "", err
 	}
 	return hex.EncodeToString(bytes), nil
-}
\ No newline at end of file
+}
+
+// ParseVisibility validates a requested visibility, rooms are private unless asked otherwise
+func ParseVisibility(v string) (Visibility, error) {
+	switch Visibility(v) {
+	case "":
+		return VisibilityPrivate, nil
+	case VisibilityPublic, VisibilityPrivate:
+		return Visibility(v), nil
+	}
+	return "", ErrInvalidVisibility
+}
+
+// TTL is how long the room lives without activity, the room of a tournament match
+// waits for its players as long as the tournament is kept
+func (r *Room) TTL() time.Duration {
+	if r.Tournament != "" {
+		return TournamentTTL
+	}
+	return RoomTTL
+}
+
+func NewRoom(id string, creator string, rules RuleSet, visibility Visibility) *Room {
+	now := time.Now()
+	return &Room{
+		ID:         id,
+		Creator:    creator,
+		CreatedAt:  now.UnixMilli(),
+		Rules:      rules,
+		Visibility: visibility,
+		Status:     RoomOpen,
+		BestOf:     1,
+		ExpiresAt:  now.Add(RoomTTL).UnixMilli(),
+	}
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MinBoardSize = 5
+	MaxBoardSize = 20
+	// GameGrace is how long a game outlives its longest timer
+	GameGrace = 2 * time.Minute
+)
+
+type GameMode string
+
+const (
+	ModeClassic GameMode = "CLASSIC"
+	ModeSalvo   GameMode = "SALVO"
+)
+
+var (
+	ErrInvalidBoardSize = errors.New("Board size must be between 5 and 20")
+	ErrInvalidTimeLimit = errors.New("Invalid time limit")
+	ErrInvalidMode      = errors.New("Invalid game mode")
+)
+
+// RuleSet holds everything that can differ between two rooms.
+// Width is the number of columns (Y) and Height the number of rows (X), limits are in seconds.
+// In salvo mode SalvoShots fixes the shots per turn, 0 means one shot per surviving ship.
+// SpectatorDelay holds back what spectators see so the audience can not help a player.
+type RuleSet struct {
+	Width          int      `json:"width"`
+	Height         int      `json:"height"`
+	Fleet          Fleet    `json:"fleet"`
+	TurnLimit      int      `json:"turnLimit"`
+	PlacementLimit int      `json:"placementLimit"`
+	ExtraShotOnHit bool     `json:"extraShotOnHit"`
+	Mode           GameMode `json:"mode"`
+	SalvoShots     int      `json:"salvoShots"`
+	SpectatorDelay int      `json:"spectatorDelay"`
+}
+
+func DefaultRuleSet() RuleSet {
+	fleet := make(Fleet, len(DefaultFleet))
+	copy(fleet, DefaultFleet)
+
+	return RuleSet{
+		Width:          10,
+		Height:         10,
+		Fleet:          fleet,
+		TurnLimit:      40,
+		PlacementLimit: 60,
+		ExtraShotOnHit: false,
+		Mode:           ModeClassic,
+		SalvoShots:     0,
+		SpectatorDelay: 0,
+	}
+}
+
+func (r RuleSet) Validate() error {
+	if r.Width < MinBoardSize || r.Width > MaxBoardSize || r.Height < MinBoardSize || r.Height > MaxBoardSize {
+		return ErrInvalidBoardSize
+	}
+
+	if len(r.Fleet) == 0 || r.Fleet.TotalCells() > r.Width*r.Height {
+		return ErrInvalidFleet
+	}
+
+	longest := max(r.Width, r.Height)
+	for _, c := range r.Fleet {
+		if c.Name == "" || c.Length <= 0 || c.Length > longest {
+			return ErrInvalidFleet
+		}
+	}
+	// fitting by area is not enough, the ships must have a layout on the board
+	if _, ok := r.Fleet.Layout(r.Width, r.Height); !ok {
+		return ErrInvalidFleet
+	}
+
+	if r.Mode != ModeClassic && r.Mode != ModeSalvo {
+		return ErrInvalidMode
+	}
+
+	if r.SalvoShots < 0 || r.SalvoShots > r.Width*r.Height {
+		return ErrInvalidMode
+	}
+
+	if r.TurnLimit < 5 || r.TurnLimit > 300 || r.PlacementLimit < 10 || r.PlacementLimit > 600 || r.SpectatorDelay < 0 || r.SpectatorDelay > 300 {
+		return ErrInvalidTimeLimit
+	}
+
+	return nil
+}
+
+func (r RuleSet) InBounds(p Point) bool {
+	return p.X >= 0 && p.X < r.Height && p.Y >= 0 && p.Y < r.Width
+}
+
+func (r RuleSet) TurnDuration() time.Duration {
+	return time.Duration(r.TurnLimit) * time.Second
+}
+
+func (r RuleSet) SpectatorDelayDuration() time.Duration {
+	return time.Duration(r.SpectatorDelay) * time.Second
+}
+
+func (r RuleSet) PlacementDuration() time.Duration {
+	return time.Duration(r.PlacementLimit) * time.Second
+}
+
+// GameTTL is how long a game is kept after its last save. It outlives the longest
+// timer of the rules so a turn or placement timeout still finds the game.
+func (r RuleSet) GameTTL() time.Duration {
+	return max(r.TurnDuration(), r.PlacementDuration()) + GameGrace
+}
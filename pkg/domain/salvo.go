@@ -0,0 +1,116 @@
+package domain
+
+import "errors"
+
+var (
+	ErrWrongMode    = errors.New("Move not allowed in this game mode")
+	ErrInvalidSalvo = errors.New("Invalid number of shots in salvo")
+)
+
+type ShotResult struct {
+	X      int       `json:"x"`
+	Y      int       `json:"y"`
+	Result CellState `json:"result"`
+}
+
+type SalvoResult struct {
+	Shots []ShotResult `json:"shots"`
+	Sunk  []PlacedShip `json:"sunk"`
+}
+
+// SalvoSize is the number of shots playerID fires this turn
+func (g *Game) SalvoSize(playerID string) int {
+	if g.Rules.SalvoShots > 0 {
+		return g.Rules.SalvoShots
+	}
+
+	afloat := 0
+	for _, s := range g.Ships[playerID] {
+		if !g.isSunk(playerID, s) {
+			afloat++
+		}
+	}
+	return afloat
+}
+
+// HandleSalvo resolves all shots of a salvo against the opponent board at once.
+// Nothing is applied unless every shot is valid, the turn always passes to the opponent.
+func (g *Game) HandleSalvo(playerID string, shots []Point) (SalvoResult, error) {
+
+	if g.Status == StatusOver {
+		return SalvoResult{}, ErrGameOver
+	}
+
+	if g.Status != StatusActive {
+		return SalvoResult{}, ErrGameNotStarted
+	}
+
+	if g.Rules.Mode != ModeSalvo {
+		return SalvoResult{}, ErrWrongMode
+	}
+
+	if g.ActivePlayer != playerID {
+		return SalvoResult{}, ErrNotYourTurn
+	}
+
+	opponentID := g.GetOpponent(playerID)
+	board, ok := g.Boards[opponentID]
+	if !ok {
+		return SalvoResult{}, ErrBoardNotFound
+	}
+
+	// a salvo can not be bigger than the cells left to shoot at
+	untouched := 0
+	for i := range board {
+		for j := range board[i] {
+			if board[i][j] == Empty || board[i][j] == Ship {
+				untouched++
+			}
+		}
+	}
+
+	if len(shots) != min(g.SalvoSize(playerID), untouched) || len(shots) == 0 {
+		return SalvoResult{}, ErrInvalidSalvo
+	}
+
+	seen := make(map[Point]bool)
+	for _, p := range shots {
+		if !g.Rules.InBounds(p) {
+			return SalvoResult{}, ErrOutOfBound
+		}
+		if seen[p] || board[p.X][p.Y] == Hit || board[p.X][p.Y] == Miss {
+			return SalvoResult{}, ErrInvalidMove
+		}
+		seen[p] = true
+	}
+
+	ships := g.Ships[opponentID]
+	sunkBefore := make([]bool, len(ships))
+	for i, s := range ships {
+		sunkBefore[i] = g.isSunk(opponentID, s)
+	}
+
+	result := SalvoResult{
+		Shots: make([]ShotResult, len(shots)),
+		Sunk:  []PlacedShip{},
+	}
+
+	for i, p := range shots {
+		if board[p.X][p.Y] == Ship {
+			board[p.X][p.Y] = Hit
+		} else {
+			board[p.X][p.Y] = Miss
+		}
+		result.Shots[i] = ShotResult{X: p.X, Y: p.Y, Result: board[p.X][p.Y]}
+	}
+
+	for i, s := range ships {
+		if !sunkBefore[i] && g.isSunk(opponentID, s) {
+			result.Sunk = append(result.Sunk, s)
+		}
+	}
+
+	g.ActivePlayer = opponentID
+
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidSeason  = errors.New("Seasons need an id of letters, digits or -, a start before the end and must not overlap")
+	ErrSeasonNotFound = errors.New("Season not found")
+)
+
+var seasonIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,32}$`)
+
+// Season is a ranked period with its own leaderboards, it runs from Start up to End
+type Season struct {
+	ID    string    `json:"id"`
+	Name  string    `json:"name,omitempty"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ReadSeasons reads a JSON list of seasons and returns them ordered by start
+func ReadSeasons(r io.Reader) ([]Season, error) {
+	var seasons []Season
+	if err := json.NewDecoder(r).Decode(&seasons); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(seasons, func(a, b Season) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	ids := make(map[string]bool)
+	for i, s := range seasons {
+		if !seasonIDPattern.MatchString(s.ID) || ids[s.ID] || !s.Start.Before(s.End) {
+			return nil, ErrInvalidSeason
+		}
+		if i > 0 && s.Start.Before(seasons[i-1].End) {
+			return nil, ErrInvalidSeason
+		}
+		ids[s.ID] = true
+	}
+	return seasons, nil
+}
+
+// Scope is the key part the leaderboards of the season are kept under
+func (s Season) Scope() string {
+	return "season-" + s.ID
+}
+
+// Active tells if the season runs at t
+func (s Season) Active(t time.Time) bool {
+	return !t.Before(s.Start) && t.Before(s.End)
+}
+
+// SeasonAt returns the season running at t, ok is false between seasons
+func SeasonAt(seasons []Season, t time.Time) (Season, bool) {
+	for _, s := range seasons {
+		if s.Active(t) {
+			return s, true
+		}
+	}
+	return Season{}, false
+}
+
+// SoftReset pulls a rating halfway back to the default rating at the start of a season,
+// games, wins and losses are kept so established players stay out of the provisional K
+func (r Rating) SoftReset() Rating {
+	r.Points = DefaultRating + (r.Points-DefaultRating)/2
+	return r
+}
@@ -0,0 +1,62 @@
+package domain
+
+import "errors"
+
+var ErrInvalidBestOf = errors.New("bestOf must be 1, 3, 5 or 7")
+
+// Series tracks the score of consecutive games played in the same room
+type Series struct {
+	BestOf    int            `json:"bestOf"`
+	Scores    map[string]int `json:"scores"`
+	Games     int            `json:"games"`
+	LastRound int            `json:"lastRound"`
+	Winner    string         `json:"winner"`
+}
+
+func ValidateBestOf(bestOf int) error {
+	switch bestOf {
+	case 1, 3, 5, 7:
+		return nil
+	}
+	return ErrInvalidBestOf
+}
+
+func NewSeries(bestOf int) *Series {
+	return &Series{
+		BestOf: bestOf,
+		Scores: make(map[string]int),
+	}
+}
+
+// Record adds the result of a finished round, a round is only counted once
+// and an empty winner counts as a played game without points.
+func (s *Series) Record(round int, winner string) bool {
+	if s.Decided() || round <= s.LastRound {
+		return false
+	}
+
+	if s.Scores == nil {
+		s.Scores = make(map[string]int)
+	}
+
+	s.LastRound = round
+	s.Games++
+	if winner != "" {
+		s.Scores[winner]++
+		if s.Scores[winner] > s.BestOf/2 {
+			s.Winner = winner
+		}
+	}
+	return true
+}
+
+func (s *Series) Decided() bool {
+	return s.Winner != ""
+}
+
+// Reset starts a new series with the same length once the last one is decided
+func (s *Series) Reset() {
+	s.Scores = make(map[string]int)
+	s.Games = 0
+	s.Winner = ""
+}
@@ -0,0 +1,352 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+	"strings"
+	"time"
+)
+
+type TournamentFormat string
+
+const (
+	SingleElimination TournamentFormat = "SINGLE_ELIMINATION"
+	DoubleElimination TournamentFormat = "DOUBLE_ELIMINATION"
+)
+
+type TournamentStatus string
+
+const (
+	TournamentRegistering TournamentStatus = "REGISTERING"
+	TournamentRunning     TournamentStatus = "RUNNING"
+	TournamentFinished    TournamentStatus = "FINISHED"
+)
+
+// Bracket is the part of a tournament a match belongs to, losers of the winners bracket
+// get a second chance in the losers bracket of double elimination tournaments
+type Bracket string
+
+const (
+	BracketWinners Bracket = "WINNERS"
+	BracketLosers  Bracket = "LOSERS"
+	BracketFinal   Bracket = "FINAL"
+)
+
+const (
+	MinTournamentPlayers = 2
+	MaxTournamentPlayers = 64
+	MaxTournamentName    = 64
+	// TournamentTTL is how long a tournament is kept after its last change
+	TournamentTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidFormat         = errors.New("format must be SINGLE_ELIMINATION or DOUBLE_ELIMINATION")
+	ErrInvalidTournamentName = errors.New("Tournament name must be 1 to 64 characters")
+	ErrTournamentSize        = errors.New("Tournaments take 2 to 64 players")
+	ErrTournamentNotFound    = errors.New("Tournament not found")
+	ErrRegistrationClosed    = errors.New("Registration of the tournament is closed")
+	ErrAlreadyRegistered     = errors.New("Player is already registered")
+	ErrTournamentFull        = errors.New("Tournament is full")
+	ErrNotEnoughPlayers      = errors.New("Tournament needs at least 2 players to start")
+	ErrMatchNotFound         = errors.New("Match not found")
+	ErrMatchNotPlayable      = errors.New("Match is decided or still waits for its players")
+	ErrNotInMatch            = errors.New("Winner must be a player of the match")
+	ErrTournamentConflict    = errors.New("Tournament was changed by another request")
+)
+
+// Slot is the seat of a match a result moves to
+type Slot struct {
+	Match string `json:"match"`
+	Seat  int    `json:"seat"`
+}
+
+// TournamentMatch is one match of the bracket. Seated tells which seats are settled,
+// a settled seat without a player is a bye and the other player moves on without playing.
+type TournamentMatch struct {
+	ID       string    `json:"id"`
+	Bracket  Bracket   `json:"bracket"`
+	Round    int       `json:"round"`
+	Players  [2]string `json:"players"`
+	Seated   [2]bool   `json:"seated"`
+	RoomID   string    `json:"roomID,omitempty"`
+	Winner   string    `json:"winner,omitempty"`
+	Done     bool      `json:"done"`
+	WinnerTo *Slot     `json:"winnerTo,omitempty"`
+	LoserTo  *Slot     `json:"loserTo,omitempty"`
+}
+
+// Playable tells if both players of the match are known and it is not decided yet
+func (m *TournamentMatch) Playable() bool {
+	return !m.Done && m.Seated[0] && m.Seated[1] && m.Players[0] != "" && m.Players[1] != ""
+}
+
+// Tournament is an elimination tournament, players are seeded in the order they registered.
+// Version works like the version of a game, a save only succeeds on the version it was loaded at.
+type Tournament struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Organizer  string            `json:"organizer"`
+	Format     TournamentFormat  `json:"format"`
+	Status     TournamentStatus  `json:"status"`
+	MaxPlayers int               `json:"maxPlayers"`
+	Rules      RuleSet           `json:"rules"`
+	BestOf     int               `json:"bestOf"`
+	Players    []string          `json:"players"`
+	Matches    []TournamentMatch `json:"matches"`
+	Winner     string            `json:"winner,omitempty"`
+	CreatedAt  int64             `json:"createdAt"`
+	Version    int64             `json:"version"`
+}
+
+// ParseTournamentFormat validates a requested format, single elimination is the default
+func ParseTournamentFormat(f string) (TournamentFormat, error) {
+	switch TournamentFormat(strings.ToUpper(f)) {
+	case "", SingleElimination:
+		return SingleElimination, nil
+	case DoubleElimination:
+		return DoubleElimination, nil
+	}
+	return "", ErrInvalidFormat
+}
+
+func NewTournament(id string, name string, organizer string, format TournamentFormat, maxPlayers int, rules RuleSet, bestOf int) (*Tournament, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > MaxTournamentName {
+		return nil, ErrInvalidTournamentName
+	}
+	if maxPlayers == 0 {
+		maxPlayers = MaxTournamentPlayers
+	}
+	if maxPlayers < MinTournamentPlayers || maxPlayers > MaxTournamentPlayers {
+		return nil, ErrTournamentSize
+	}
+	if err := ValidateBestOf(bestOf); err != nil {
+		return nil, err
+	}
+
+	return &Tournament{
+		ID:         id,
+		Name:       name,
+		Organizer:  organizer,
+		Format:     format,
+		Status:     TournamentRegistering,
+		MaxPlayers: maxPlayers,
+		Rules:      rules,
+		BestOf:     bestOf,
+		Players:    []string{},
+		Matches:    []TournamentMatch{},
+		CreatedAt:  time.Now().UnixMilli(),
+	}, nil
+}
+
+// Register adds a player while registration is open
+func (t *Tournament) Register(playerID string) error {
+	if t.Status != TournamentRegistering {
+		return ErrRegistrationClosed
+	}
+	if slices.Contains(t.Players, playerID) {
+		return ErrAlreadyRegistered
+	}
+	if len(t.Players) >= t.MaxPlayers {
+		return ErrTournamentFull
+	}
+	t.Players = append(t.Players, playerID)
+	return nil
+}
+
+// Start closes the registration and draws the bracket, players without an opponent
+// in the first round get a bye
+func (t *Tournament) Start() error {
+	if t.Status != TournamentRegistering {
+		return ErrRegistrationClosed
+	}
+	if len(t.Players) < MinTournamentPlayers {
+		return ErrNotEnoughPlayers
+	}
+
+	size := 2
+	for size < len(t.Players) {
+		size *= 2
+	}
+	t.Status = TournamentRunning
+	t.buildBracket(size)
+
+	seeds := make([]string, size)
+	copy(seeds, t.Players)
+	for k := 0; k < size/2; k++ {
+		first := matchID(BracketWinners, 1, k)
+		t.seat(Slot{Match: first, Seat: 0}, seeds[k])
+		t.seat(Slot{Match: first, Seat: 1}, seeds[size-1-k])
+	}
+	return nil
+}
+
+// buildBracket creates every match of a bracket for size players and wires where
+// winners and losers go. The losers bracket alternates between rounds among its own
+// players and rounds against the players dropping out of the winners bracket. The
+// reset final of double elimination is only added once it is needed, see advance.
+func (t *Tournament) buildBracket(size int) {
+	rounds := bits.Len(uint(size)) - 1
+	double := t.Format == DoubleElimination
+	final := Slot{Match: matchID(BracketFinal, 1, 0)}
+
+	for r := 1; r <= rounds; r++ {
+		count := size >> r
+		for k := 0; k < count; k++ {
+			m := TournamentMatch{ID: matchID(BracketWinners, r, k), Bracket: BracketWinners, Round: r}
+			switch {
+			case r < rounds:
+				m.WinnerTo = &Slot{Match: matchID(BracketWinners, r+1, k/2), Seat: k % 2}
+			case double:
+				m.WinnerTo = &Slot{Match: final.Match, Seat: 0}
+			}
+
+			if double {
+				switch {
+				case rounds == 1:
+					m.LoserTo = &Slot{Match: final.Match, Seat: 1}
+				case r == 1:
+					m.LoserTo = &Slot{Match: matchID(BracketLosers, 1, k/2), Seat: k % 2}
+				default:
+					// crossed so players do not meet the same opponent again right away
+					m.LoserTo = &Slot{Match: matchID(BracketLosers, 2*(r-1), count-1-k), Seat: 1}
+				}
+			}
+			t.Matches = append(t.Matches, m)
+		}
+	}
+	if !double {
+		return
+	}
+
+	for j := 1; j < rounds; j++ {
+		count := size >> (j + 1)
+		for k := 0; k < count; k++ {
+			t.Matches = append(t.Matches, TournamentMatch{
+				ID:       matchID(BracketLosers, 2*j-1, k),
+				Bracket:  BracketLosers,
+				Round:    2*j - 1,
+				WinnerTo: &Slot{Match: matchID(BracketLosers, 2*j, k), Seat: 0},
+			})
+		}
+		for k := 0; k < count; k++ {
+			m := TournamentMatch{ID: matchID(BracketLosers, 2*j, k), Bracket: BracketLosers, Round: 2 * j}
+			if j < rounds-1 {
+				m.WinnerTo = &Slot{Match: matchID(BracketLosers, 2*j+1, k/2), Seat: k % 2}
+			} else {
+				m.WinnerTo = &Slot{Match: final.Match, Seat: 1}
+			}
+			t.Matches = append(t.Matches, m)
+		}
+	}
+	t.Matches = append(t.Matches, TournamentMatch{ID: final.Match, Bracket: BracketFinal, Round: 1})
+}
+
+// matchID names a match by bracket, round and position, W2-1 is the first match of
+// the second winners round
+func matchID(b Bracket, round int, k int) string {
+	return fmt.Sprintf("%c%d-%d", b[0], round, k+1)
+}
+
+// Match returns the match with the id, nil when there is none
+func (t *Tournament) Match(id string) *TournamentMatch {
+	for i := range t.Matches {
+		if t.Matches[i].ID == id {
+			return &t.Matches[i]
+		}
+	}
+	return nil
+}
+
+// MatchByRoom returns the match played in the room, nil when there is none
+func (t *Tournament) MatchByRoom(roomID string) *TournamentMatch {
+	for i := range t.Matches {
+		if t.Matches[i].RoomID == roomID {
+			return &t.Matches[i]
+		}
+	}
+	return nil
+}
+
+// Playable returns the matches whose players are known and that still need a room
+func (t *Tournament) Playable() []*TournamentMatch {
+	var ready []*TournamentMatch
+	for i := range t.Matches {
+		if m := &t.Matches[i]; m.Playable() && m.RoomID == "" {
+			ready = append(ready, m)
+		}
+	}
+	return ready
+}
+
+// Report decides a match, the winner moves on and the loser drops to the losers
+// bracket or is out
+func (t *Tournament) Report(matchID string, winner string) error {
+	m := t.Match(matchID)
+	if m == nil {
+		return ErrMatchNotFound
+	}
+	if t.Status != TournamentRunning || !m.Playable() {
+		return ErrMatchNotPlayable
+	}
+	if winner != m.Players[0] && winner != m.Players[1] {
+		return ErrNotInMatch
+	}
+	t.advance(m, winner)
+	return nil
+}
+
+func (t *Tournament) advance(m *TournamentMatch, winner string) {
+	loser := m.Players[0]
+	if loser == winner {
+		loser = m.Players[1]
+	}
+	m.Winner = winner
+	m.Done = true
+
+	if m.WinnerTo == nil {
+		// the losers bracket finalist beating the winners bracket champion is the first
+		// loss of the champion, so the two meet again in a reset final
+		if m.Bracket == BracketFinal && m.Round == 1 && winner == m.Players[1] {
+			t.resetFinal(m.Players)
+			return
+		}
+		t.Winner = winner
+		t.Status = TournamentFinished
+		return
+	}
+	t.seat(*m.WinnerTo, winner)
+	if m.LoserTo != nil {
+		t.seat(*m.LoserTo, loser)
+	}
+}
+
+// resetFinal adds the second grand final of a double elimination tournament, the
+// players keep their seats of the first one
+func (t *Tournament) resetFinal(players [2]string) {
+	id := matchID(BracketFinal, 2, 0)
+	t.Matches = append(t.Matches, TournamentMatch{ID: id, Bracket: BracketFinal, Round: 2})
+	t.seat(Slot{Match: id, Seat: 0}, players[0])
+	t.seat(Slot{Match: id, Seat: 1}, players[1])
+}
+
+// seat settles a seat with a player, an empty player is a bye. Matches with a bye
+// are decided right away.
+func (t *Tournament) seat(s Slot, playerID string) {
+	m := t.Match(s.Match)
+	m.Players[s.Seat] = playerID
+	m.Seated[s.Seat] = true
+	if !m.Seated[0] || !m.Seated[1] {
+		return
+	}
+
+	switch {
+	case m.Players[0] == "":
+		t.advance(m, m.Players[1])
+	case m.Players[1] == "":
+		t.advance(m, m.Players[0])
+	}
+}
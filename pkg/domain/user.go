@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MinPasswordLength = 8
+	// MaxPasswordLength is what bcrypt can hash, longer passwords would be cut silently
+	MaxPasswordLength = 72
+	MaxDisplayName    = 32
+)
+
+var (
+	ErrUserNotFound       = errors.New("User not found")
+	ErrUsernameTaken      = errors.New("Username is already taken")
+	ErrInvalidCredentials = errors.New("Invalid username or password")
+	ErrInvalidUsername    = errors.New("Username must be 3 to 20 letters, digits or _")
+	ErrInvalidPassword    = errors.New("Password must be 8 to 72 bytes long")
+	ErrInvalidDisplayName = errors.New("Display name must be 1 to 32 characters")
+	ErrInvalidAvatarURL   = errors.New("Avatar URL must be an http or https URL")
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_]{3,20}$`)
+
+// User is a registered player, ID is the player id the user plays with.
+// The password hash is never sent to a client.
+type User struct {
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	DisplayName  string `json:"displayName"`
+	AvatarURL    string `json:"avatarURL,omitempty"`
+	PasswordHash []byte `json:"-"`
+	CreatedAt    int64  `json:"createdAt"`
+	Rating       Rating `json:"rating"`
+}
+
+// NewUser validates the profile of a new user, the display name defaults to the username
+func NewUser(id string, username string, displayName string, avatarURL string) (*User, error) {
+	username = NormalizeUsername(username)
+	if !usernamePattern.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+	if strings.TrimSpace(displayName) == "" {
+		displayName = username
+	}
+
+	u := &User{
+		ID:        id,
+		Username:  username,
+		CreatedAt: time.Now().UnixMilli(),
+		Rating:    NewRating(),
+	}
+	if err := u.SetProfile(displayName, avatarURL); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// NormalizeUsername makes usernames case insensitive
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// SetProfile changes the display name and avatar, an empty avatar URL removes the avatar
+func (u *User) SetProfile(displayName string, avatarURL string) error {
+	displayName = strings.TrimSpace(displayName)
+	if n := utf8.RuneCountInString(displayName); n == 0 || n > MaxDisplayName {
+		return ErrInvalidDisplayName
+	}
+
+	avatarURL = strings.TrimSpace(avatarURL)
+	if avatarURL != "" {
+		parsed, err := url.Parse(avatarURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return ErrInvalidAvatarURL
+		}
+	}
+
+	u.DisplayName = displayName
+	u.AvatarURL = avatarURL
+	return nil
+}